@@ -1,11 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"sort"
 	"strings"
@@ -24,7 +26,7 @@ import (
 var description = `
 Extracts text from binary, including optionally UTF-8/16, with controls and output options.
 
-ASCII mode grabs lower-bit characters.  A few control characters and 0x20 - 0x7E.
+ASCII mode grabs lower-bit characters.  A few control characters and 0x20 - 0x7E.  (-charset changes which.)
 UTF8 mode accepts those and recognizes possible UTF-8, using the encoding standard.
 
 Inspired by the 1985-86 ASCII.exe program from SEA (System Enhancement Associates) of ARC (pre-PKZIP fame), 
@@ -38,16 +40,42 @@ This will extract standard ASCII, no UTF-8/16, that is six characters or longer,
 USAGE NOTES
 (Beyond what's in the parameter help.)
 
+ Inputs are any number of files, directories and masks, e.g. "ascii -x file1 dir2 'mask*'".  - reads standard input,
+e.g. "cat foo.bin | ascii -".  Flags can go before or after them.  After --, everything is an input.
+-files-from reads the paths to scan from a file or standard input, e.g. "find . -mtime -1 -print0 | ascii -files-from - -null".
+Those are exact paths, not masks.
+
+ -include, -exclude and -exclude-dir select what directory scans read, and can each be given more than once,
+e.g. "ascii -r -x . -include '**/*.dll' -exclude 'tests/**' -exclude-dir node_modules -exclude-dir .git".
+A .asciiignore file in a directory lists what to skip there and below, as a .gitignore does.  (-gitignore follows
+those too, -no-ignore neither.)
+
  -o, -p are for writing found text to files.  -o puts it next to the original, -p puts it in a flattened name 
-in the specified directory.  Good for indexing search data.
+in the specified directory.  Good for indexing search data.  The files written are listed in a .asciioutputs file in
+each directory written to, and later scans skip them.  (See -scan-outputs.)
+
+ -suppress-set enables curated lists of boilerplate, e.g. "-suppress-set fonts,ooxml" for Word documents,
+"pdf" for PDFs, "compiler,msvc" for executables.  They can be combined with -suppress.
+
+ -learn-boilerplate reads everything twice: once to find strings common to most files, and again to output.
+Save what it learned with -boilerplate-out, and reuse it in later runs with -boilerplate-in instead of relearning.
 
  -skip-older-match is for when there are different revisions of the same file, with the version or date in the 
-file name.  As long as it's at the END of the file name, this can be used to scan only the most recent (by
-file modification time.)  e.g. for foo@2.0.db, use "@", for foo(2023-12-12).rtf use "(".  This is useful for
-creating text indexes of non-text files.  (e.g. allowing Spotlight to index non-text documents with some text in them.)
+file name.  As long as it's at the END of the file name, this can be used to scan only the most recent (by the
+date or version number in the name, else by file modification time.)  e.g. for foo@2.0.db, use "@", for 
+foo(2023-12-12).rtf use "(".  This is useful for creating text indexes of non-text files.  (e.g. allowing Spotlight
+to index non-text documents with some text in them.)  -version-key does the same with a regular expression for the
+version, wherever it is in the name.  -keep-newest keeps more than one, -version-across-dirs compares between directories.
+
+EXIT STATUS
+0 if any strings were found (or with -f, any hits), 1 if none, 2 if there were errors.  Errors go to stderr,
+and are listed again at the end of the run.
+
+GNU STRINGS
+Run as "strings" (e.g. through a symlink), or with -strings as the first option, this takes binutils strings'
+options and gives its output: -n, -a, -t {o,d,x}, -e {s,S,b,l,B,L}, -f, -w, -o.  e.g. ascii -strings -t x -n 8 foo.bin
 
 TROUBLE-SHOOTING
-If some flags/options don't appear to be working, list them before the input file mask.
 If using a mask and it isn't working, either enclose the name in quotes or disable shell globbing to prevent the
 shell expanding the file list.  (e.g., in zsh, call as "noglob ascii ...")
 
@@ -70,13 +98,15 @@ shell expanding the file list.  (e.g., in zsh, call as "noglob ascii ...")
 */
 
 var (
-	baseNames    []string
 	debugOutput  = false
 	writeFiles   = false
 	writePath    = ""
 	writeVerbose = false
 	writeOffset  = false
 	startPath    = ""
+
+	sortBy        = SORTBY_DATE // Order files and subdirectories are scanned in.
+	sortAscending = false
 	// Used for file masks.
 	// Debug Mode Data - used for extra-verbose output.
 	includedFileNames []string
@@ -86,6 +116,27 @@ var (
 	noExpansion       = false // Set to true to skip expanding DOC and other PK files.  Does not recursively enter them though.
 	stringCount       = 0
 	utf16StringCount  = 0
+
+	minRunes      = 0       // Minimum characters, as opposed to minLen's bytes.
+	minWords      = 0       // Minimum whitespace-separated words.
+	maxLength     = 0       // Maximum characters in a string.  0 is no limit.
+	maxLengthMode = "split" // What to do with longer strings: split, truncate or drop.
+	joinGap       = 0       // Join runs separated by at most this many non-text bytes.  0 is off.
+
+	newlineMode     = "keep" // Newlines inside strings: keep, split or escape.
+	recordSeparator = "\n"   // Between output strings.  NUL for -0.
+
+	wideCharBytes       = 2     // UTF-16.  4 for UTF-32 in GNU strings mode.
+	wideBigEndian       = false // The original search is little-endian: ASCII, then 00.
+	wideTerminated      = true  // Require a 00 00 terminator on UTF-16 strings.
+	wideOnly            = false // Only wide strings, no 8-bit ones.  GNU strings -e b/l/B/L.
+	latin1HighBytes     = true  // High bytes allowed by -charset are read as Latin-1.  Otherwise output as-is.
+	stringsOffsetFormat = "%7x" // GNU strings -t
+
+	withFileName = false // -H: grep-style path[:member]:offset:string lines.
+	listFiles    = false // -l: only list files with hits.
+	countHits    = false // -c: only count hits per file.
+	listedFiles  = make(map[string]bool)
 )
 
 // These are filled in by the build script
@@ -104,6 +155,13 @@ func IIF(cond bool, str1 string, str2 string) string {
 	return str2
 }
 
+func IIFInt(cond bool, int1 int, int2 int) int {
+	if cond {
+		return int1
+	}
+	return int2
+}
+
 func PrintHelp() {
 	fmt.Fprintf(os.Stderr, "%s\n", description)
 	flag.PrintDefaults()
@@ -114,10 +172,12 @@ type SORTBY int
 const SORTBY_NONE SORTBY = 0
 const SORTBY_NAME SORTBY = 1
 const SORTBY_DATE SORTBY = 2
+const SORTBY_SIZE SORTBY = 3
+
+var sortByNames = map[string]SORTBY{"none": SORTBY_NONE, "name": SORTBY_NAME, "mtime": SORTBY_DATE, "size": SORTBY_SIZE}
 
 // For directory recursion, need the current directory and list of subdirs in it, and to go through them.
-// subdirs and files are the names within target, not the full path.
-// DOES NOT SUPPORT FILEMASK YET
+// subdirs and files are the names within target, not the full path.  Both are in sortby order.
 func filesInDirectory(target string, filemask string, sortby SORTBY, ascending bool) (subdirs []string, files []string) {
 	var filelist []fs.DirEntry
 
@@ -127,39 +187,42 @@ func filesInDirectory(target string, filemask string, sortby SORTBY, ascending b
 		filelist, err = pFile.ReadDir(0)
 	}
 
-	if sortby == SORTBY_DATE {
-		if ascending {
-			sort.Slice(filelist, func(i, j int) bool {
-				fileI, _ := filelist[i].Info()
-				fileJ, _ := filelist[j].Info()
-				return fileI.ModTime().Before(fileJ.ModTime())
-			})
-		} else {
-			sort.Slice(filelist, func(i, j int) bool {
-				fileI, _ := filelist[i].Info()
-				fileJ, _ := filelist[j].Info()
-				return fileJ.ModTime().Before(fileI.ModTime())
-			})
-		}
-	} else { // Name sort
-		if ascending {
-			sort.Slice(filelist, func(i, j int) bool {
-				return filelist[i].Name() < filelist[j].Name()
-			})
-		} else if sortby == SORTBY_NAME {
-			sort.Slice(filelist, func(i, j int) bool {
-				return filelist[i].Name() > filelist[j].Name()
-			})
+	if sortby != SORTBY_NONE {
+		infos := make(map[string]fs.FileInfo, len(filelist)) // Symlinks by their targets.
+		for _, f := range filelist {
+			if info, err := os.Stat(filepath.Join(target, f.Name())); err == nil {
+				infos[f.Name()] = info
+			} else if info, err := f.Info(); err == nil {
+				infos[f.Name()] = info
+			}
 		}
+		sort.SliceStable(filelist, func(i, j int) bool {
+			nameI, nameJ := filelist[i].Name(), filelist[j].Name()
+			compare := 0
+			if infoI, infoJ := infos[nameI], infos[nameJ]; infoI != nil && infoJ != nil {
+				if sortby == SORTBY_DATE {
+					compare = infoI.ModTime().Compare(infoJ.ModTime())
+				} else if sortby == SORTBY_SIZE {
+					compare = cmp.Compare(infoI.Size(), infoJ.Size())
+				}
+			}
+			if compare == 0 { // Names break ties, so the order is the same every run.
+				compare = strings.Compare(nameI, nameJ)
+			}
+			if ascending {
+				return compare < 0
+			}
+			return compare > 0
+		})
 	}
 
 	// Iterate through all files, matching and then sort
 	if err == nil {
 		for _, f := range filelist {
-			if f.IsDir() {
-				f.Info()
+			isDir, isFile := classifyEntry(target, f)
+			if isDir {
 				subdirs = append(subdirs, f.Name())
-			} else {
+			} else if isFile {
 				if len(filemask) > 0 {
 					res, err := filepath.Match(filemask, f.Name())
 					if err == nil && res {
@@ -175,28 +238,88 @@ func filesInDirectory(target string, filemask string, sortby SORTBY, ascending b
 }
 
 func main() {
-	var pInputFilename = flag.String("i", "", "Filename or mask to parse.  Assumes CWD.")
+	var pInputFilename = flag.String("i", "", "Filename or mask to parse.  Assumes CWD.  Inputs can also be listed without -i, and - is standard input.")
 	var pMinLen = flag.Int("min-len", 6, "How many ASCII (or UTF) characters must be found in a row to make a qualifying string. Too few and you'll get a lot of junk.")
 	var pWriteOutput = flag.Bool("o", false, "Should files, less extension plus .txt, be written?  Default: False")
 	var pWriteOutputPath = flag.String("p", "", "Path to write output to, if different from source.  Implies flattening from dir1/dir2/filename to dir1-dir2-filename.")
 	var putf8 = flag.Bool("utf8", false, "Include value UTF8 characters.  (Default is pure lower-bit ASCII.)\nWarning: Lots of junk looks like UTF-8.  Non-UTF8 is usually cleaner.")
-	var putf16 = flag.Bool("utf16", false, "Look for UTF-16 (BE) strings.  Only handles ASCII-ish ones.")
+	var putf16 = flag.Bool("utf16", false, "Look for UTF-16 (LE) strings.  Only handles ASCII-ish ones.")
 	var pSkipOlderMatch = flag.String("skip-older-match", "", "For files with dates/incrementors in their names, allows grabbing the newest that don't differ until this string.\n(e.g. notes_20220212.txt, notes_20211220.txt, pass '_' and only the newest is processed.  Case-Sensitive.)\nPer Directory.")
+	var pVersionKey = flag.String("version-key", "", "Like -skip-older-match, with a regular expression for the version part of file names.  The first group, if any.\ne.g. \"_(\\d{8})\\.\" or \"-v([\\d.]+)\"")
+	flag.IntVar(&keepNewest, "keep-newest", 1, "With -skip-older-match or -version-key, how many of the newest versions to keep.")
+	flag.BoolVar(&versionAcrossDirs, "version-across-dirs", false, "With -skip-older-match or -version-key, compare versions of the same name in every directory, not just within one.")
 	var pAlphaRatio = flag.Int("alpha-ratio", 0, "Percentage required alphanumeric+,. in a string.  Default is 0 - no requirement.  80 should reduce noise.")
+	var pPlausibility = flag.Int("plausibility", 0, "Minimum natural-language plausibility score, 0-100, from a character model trained on embedded text.\nDefault is 0 - no requirement.  50 keeps most prose and drops most binary noise.")
+	var pWordRatio = flag.Int("word-ratio", 0, "Percentage of words in a string that must be dictionary words.  Default is 0 - no requirement.")
+	var pDictWords = flag.Int("dict-words", 0, "Number of dictionary words a string must contain.  Default is 0 - no requirement.")
+	var pDictLang = flag.String("dict-lang", "en", "Comma-delimited built-in word lists for -word-ratio/-dict-words: en, de, fr, es.\nEach has about 1000 common words.  Add a fuller list with -dict lang=file.")
+	var pDictFiles = flag.String("dict", "", "Comma-delimited custom word list files, one word per line, added to the built-in lists.\nUse lang=file (e.g. de=fachbegriffe.txt) to only load a list when that -dict-lang is selected.")
+	var pMinRunes = flag.Int("min-runes", 0, "Minimum characters (not bytes, as -min-len counts) in a qualifying string.  Default is 0 - no requirement.")
+	var pMinWords = flag.Int("min-words", 0, "Minimum whitespace-separated words in a qualifying string.  Default is 0 - no requirement.")
+	var pMaxLen = flag.Int("max-len", 0, "Maximum characters in a string.  Default is 0 - no limit.  See -max-len-mode.")
+	var pMaxLenMode = flag.String("max-len-mode", "split", "What to do with strings over -max-len: split (into -max-len pieces), truncate, or drop.")
+	var pLearnBoilerplate = flag.Int("learn-boilerplate", 0, "Two-pass scan: first count the files each string occurs in, then suppress strings found in more than\nthis percentage of files.  Default is 0 - off.  Best with many files from one application.")
+	var pBoilerplateOut = flag.String("boilerplate-out", "", "Save the strings learned by -learn-boilerplate to this file.")
+	var pBoilerplateIn = flag.String("boilerplate-in", "", "Suppress the strings in a file saved with -boilerplate-out.")
+	var pTag = flag.Bool("tag", false, "Preface strings with the indicators found in them, e.g. [url,domain].  See -only-tags.")
+	var pOnlyTags = flag.String("only-tags", "", "Only output strings with these comma-delimited tags, or any tag with \"any\".\nTags: "+strings.Join(IndicatorNames(), ", ")+".\nGroups: ip, path, hash.")
+	var pSecrets = flag.String("secrets", "", "Detect secrets and credentials (AWS/GitHub/Slack keys, private keys, password=...).\nreport: print each one found.  redact: mask them in the output, including -o/-p files, with a per-file summary.\nOr both: report,redact.")
+	var pPII = flag.String("pii", "", "Detect personal data (emails, phone numbers, card numbers, SSN/NINO/SIN, IBANs).\nreport: print each one found.  redact: mask them in the output, including -o/-p files, with a per-file summary.")
 	var pRecurseDirs = flag.Bool("r", false, "Recurse Directories")
+	flag.Var(&includePatterns, "include", "Only scan files whose path (relative to the input directory) matches this pattern.  Repeatable.\n** matches any directories, e.g. \"docs/**/*.one\".  Without a /, matches the file name, e.g. \"*.docx\".")
+	flag.Var(&excludePatterns, "exclude", "Don't scan files matching this pattern, as for -include.  Repeatable.")
+	flag.BoolVar(&followSymlinks, "follow", false, "Follow symlinks to directories when recursing.  Loops are detected and skipped.")
+	flag.BoolVar(&oneFileSystem, "one-file-system", false, "Don't recurse into directories on other file systems (mounts), like find -xdev.")
+	flag.BoolVar(&readSpecialFiles, "read-special", false, "Read FIFOs, sockets and devices found while scanning directories.  They're skipped by default.")
+	var pMinSize = flag.String("min-size", "", "Skip files smaller than this many bytes.  K, M, G suffixes, e.g. 10K.")
+	var pMaxSize = flag.String("max-size", "", "Skip files larger than this many bytes.  K, M, G suffixes, e.g. 500M.")
+	var pModifiedSince = flag.String("modified-since", "", "Only files modified at or after this time: a date (2024-01-31), date and time (\"2024-01-31 12:00\"), or age (7d, 12h).")
+	var pModifiedBefore = flag.String("modified-before", "", "Only files modified before this time, as for -modified-since.")
+	var pOnlyTypes = flag.String("only-types", "", "Only read files of these comma-delimited content types, sniffed from their first bytes.\nTypes: "+strings.Join(contentTypes, ", ")+".  (Office documents are archives.)")
+	var pSkipTypes = flag.String("skip-types", "", "Don't read files of these content types, e.g. \"text,video,audio\".")
+	flag.BoolVar(&scanOutputs, "scan-outputs", false, "Scan files listed in .asciioutputs, which earlier -o/-p runs wrote and are otherwise skipped.")
+	var pSort = flag.String("sort", "mtime", "Order to scan files and subdirectories in: name, mtime, size or none (as the directory lists them).")
+	var pSortOrder = flag.String("sort-order", "", "asc or desc.  Default is desc (newest first) for mtime, asc otherwise.")
+	var pGitignore = flag.Bool("gitignore", false, "Also follow .gitignore files, as well as .asciiignore.")
+	var pNoIgnore = flag.Bool("no-ignore", false, "Don't read .asciiignore (or .gitignore) files.")
+	flag.Var(&excludeDirPatterns, "exclude-dir", "Don't enter directories matching this pattern, e.g. node_modules or .git.  Repeatable.")
+	flag.StringVar(&filesFrom, "files-from", "", "Also scan the paths listed in this file, one per line, or - for standard input.  e.g. from find.")
+	flag.BoolVar(&filesFromNull, "null", false, "The -files-from list is NUL-delimited, e.g. from find -print0.")
 	var pVerbose = flag.Bool("v", false, "Verbose + Writes the output to stdout.  StdOut is always on if not writing files, but defaults off otherwise.")
 	var pDebug = flag.Bool("d", false, "Debug: Output directories and filenames, and stats.")
 	var pShowOffset = flag.Bool("x", false, "Hex Offset: Preface matches with their file offset.")
 	var pSearchList = flag.String("f", "", "Filter: Comma-delimited list of strings to find.  If not provided, all strings are returned.")
-	var pSuppressList = flag.String("suppress", "", "Comma-delimited list of strings to suppress.  e.g. font names.  Matching strings are not reported.")
+	var pCharset = flag.String("charset", "", "Bytes accepted as ASCII characters.  Comma-delimited named classes, bytes (0x0D, 13, @) and ranges (0x20-0x7E).\n+item adds to the default (0x20-0x7E, tab, LF), -item removes from it, e.g. \"+cr,+ff\" or \"-lf\".\nClasses: "+strings.Join(CharsetClassNames(), ", ")+".")
+	var pNewlines = flag.String("newlines", "keep", "Newlines (CR/LF) inside strings: keep, split (each line is a separate string), or escape (as \\n and \\r).")
+	var pNullSeparator = flag.Bool("0", false, "Separate output strings with NUL instead of newline, for xargs -0 and the like.")
+	var pEscape = flag.String("escape", "raw", "Output escaping: raw, c (C-style, \\ooo for other bytes), json (JSON string escapes),\nor ascii (json plus \\uXXXX for non-ASCII characters).")
+	flag.BoolVar(&withFileName, "H", false, "With File Name: Output grep-style path[:member]:offset:string lines.  Same as -with-filename.")
+	flag.BoolVar(&withFileName, "with-filename", false, "Same as -H.")
+	flag.BoolVar(&listFiles, "l", false, "List: Only output the names of files with hits (strings that pass all filters), like grep -l.")
+	flag.BoolVar(&countHits, "c", false, "Count: Only output path[:member]:count of hits per file, like grep -c.")
+	var pJoinGap = flag.Int("join-gap", 0, "Join runs of text separated by at most this many non-text bytes into one string, e.g. sentences broken up by\nformatting codes in Word or OneNote files.  -min-len etc. apply to the joined string.  -x shows the gaps.")
+	var pContext = flag.Int("C", 0, "Context: With -f, also show this many strings before and after each hit, grep-style.")
+	var pHexContext = flag.Int("hex-context", 0, "Hex Context: Follow each hit with a hex dump of it and this many bytes either side.")
+	var pSuppressList = flag.String("suppress", "", "Comma-delimited list of strings to suppress.  e.g. font names.  Matching strings are not reported.  * is a wildcard.")
+	var pSuppressSets = flag.String("suppress-set", "", "Comma-delimited built-in suppression sets, or all: "+strings.Join(SuppressionSetNames(), ", ")+".")
 	var pNoExpansions = flag.Bool("nozip", false, "Don't expand compressed files such as DOCX, XLSX...")
 	var pVersion = flag.Bool("version", false, "Print version information.")
 
+	if IsStringsInvocation(os.Args) {
+		args := os.Args[1:]
+		if len(args) > 0 && (args[0] == "-strings" || args[0] == "--strings") {
+			args = args[1:]
+		}
+		os.Exit(StringsMain(args))
+	}
+
 	flag.Usage = func() {
 		PrintHelp()
 	}
-	flag.Parse()
-	InputFileName := *pInputFilename
+	inputs := ParseCommandLine(os.Args[1:])
+	if len(*pInputFilename) > 0 {
+		inputs = append([]string{*pInputFilename}, inputs...)
+	}
 	minLen := *pMinLen
 	minStr := 6
 	debugOutput = *pDebug
@@ -205,96 +328,194 @@ func main() {
 	writeVerbose = *pVerbose
 	writeOffset = *pShowOffset
 	noExpansion = *pNoExpansions
+	useIgnoreFiles = !*pNoIgnore
+	if *pGitignore {
+		ignoreFileNames = append(ignoreFileNames, ".gitignore")
+	}
+	showTags = *pTag
+	contextStrings = *pContext
+	joinGap = *pJoinGap
+	newlineMode = strings.ToLower(*pNewlines)
+	escapeMode = strings.ToLower(*pEscape)
+	if *pNullSeparator {
+		recordSeparator = "\x00"
+	}
+	hexContext = *pHexContext
+	minPlausibility = *pPlausibility
+	minWordRatio = *pWordRatio
+	minDictWords = *pDictWords
+	minRunes = *pMinRunes
+	minWords = *pMinWords
+	maxLength = *pMaxLen
+	maxLengthMode = strings.ToLower(*pMaxLenMode)
 
 	if *pVersion { // Print about data and exit.
 		fmt.Printf("ascii by robomac version %s\n", IIF(len(GitTag) == 0, "0.0", GitTag))
 		os.Exit(0)
 	}
 
-	if len(os.Args) == 2 && len(InputFileName) == 0 { // Not enough for a flag
-		InputFileName = os.Args[1]
-	}
 	// DirectoryInfo folder = new DirectoryInfo(Directory.GetCurrentDirectory());
 	if debugOutput {
-		fmt.Printf("All command-line options:\n")
+		Debugf("All command-line options:\n")
 		flag.VisitAll(func(f *flag.Flag) {
-			fmt.Printf("%s: %s\n", f.Name, f.Value)
+			Debugf("%s: %s\n", f.Name, f.Value)
 		})
-		fmt.Printf("Full Line:\n%s\n", os.Args)
+		Debugf("Full Line:\n%s\n", os.Args)
 	}
-	folder, _ := os.Getwd()
-	fileName := InputFileName
 	if minLen > 1 {
 		minStr = minLen
 	}
 
-	if len(InputFileName) == 0 {
+	var listedPaths []string
+	if len(filesFrom) > 0 {
+		if filesFrom == "-" && slices.Contains(inputs, "-") {
+			Fatal("Error: Standard input can't be both -files-from and an input.")
+		}
+		paths, err := ReadFilesFrom(filesFrom, filesFromNull)
+		if err != nil {
+			Fatal("Error: Could not read -files-from list: %s", err.Error())
+		}
+		listedPaths = paths
+	}
+
+	if len(inputs) == 0 && len(filesFrom) == 0 {
 		if debugOutput {
-			fmt.Printf("i = %s, minLen = %d, o = %s\n", InputFileName, minLen, IIF(writeFiles, "true", "false"))
+			Debugf("minLen = %d, o = %s\n", minLen, IIF(writeFiles, "true", "false"))
 		}
-		fmt.Printf("Error: input is required.  (The program can't do much without it.)\n")
+		fmt.Fprintf(os.Stderr, "Error: input is required.  (The program can't do much without it.)\n")
 		PrintHelp()
-		return
+		os.Exit(EXIT_ERROR)
+	}
+	var err error
+	if len(*pSearchList) > *pMinLen {
+		searchStringsList = strings.Split(strings.ToUpper(*pSearchList), ",")
+	}
+	if len(*pSuppressList) > *pMinLen {
+		suppressList = strings.Split(strings.ToUpper(*pSuppressList), ",")
+	}
+	if asciiCharset, err = ParseCharset(*pCharset); err != nil {
+		Fatal("Error: %s", err.Error())
 	}
-	if strings.Contains(InputFileName, string(os.PathSeparator)) { // Replace CWD with specified.
-		pathName := filepath.Dir(InputFileName)
-		if len(pathName) == 0 {
-			fmt.Printf("Error: Invalid input path.\n")
-			return
+	if minFileSize, err = ParseSize(*pMinSize); err != nil {
+		Fatal("Error: -min-size: %s", err.Error())
+	}
+	if maxFileSize, err = ParseSize(*pMaxSize); err != nil {
+		Fatal("Error: -max-size: %s", err.Error())
+	}
+	if modifiedSince, err = ParseTime(*pModifiedSince); err != nil {
+		Fatal("Error: -modified-since: %s", err.Error())
+	}
+	if modifiedBefore, err = ParseTime(*pModifiedBefore); err != nil {
+		Fatal("Error: -modified-before: %s", err.Error())
+	}
+	if onlyTypes, err = ParseContentTypes(*pOnlyTypes); err != nil {
+		Fatal("Error: -only-types: %s", err.Error())
+	}
+	if skipTypes, err = ParseContentTypes(*pSkipTypes); err != nil {
+		Fatal("Error: -skip-types: %s", err.Error())
+	}
+	if len(*pVersionKey) > 0 {
+		if versionPattern, err = regexp.Compile(*pVersionKey); err != nil {
+			Fatal("Error: -version-key: %s", err.Error())
 		}
-		folder = pathName
-		fileName = filepath.Base(InputFileName)
+	}
+	if sortByValue, found := sortByNames[strings.ToLower(*pSort)]; found {
+		sortBy = sortByValue
 	} else {
-		folder, _ = os.Getwd()
+		Fatal("Error: -sort must be name, mtime, size or none, not %s.", *pSort)
 	}
-	startPath = folder
-	if debugOutput {
-		fmt.Printf("Directory: %s", folder)
+	switch strings.ToLower(*pSortOrder) {
+	case "":
+		sortAscending = sortBy != SORTBY_DATE
+	case "asc":
+		sortAscending = true
+	case "desc":
+		sortAscending = false
+	default:
+		Fatal("Error: -sort-order must be asc or desc, not %s.", *pSortOrder)
 	}
-	_, err := os.Stat(folder)
-	if err != nil {
-		fmt.Printf("ASCII Error: CWD not found! %s\n", err.Error())
-		return
+	if keepNewest < 1 {
+		Fatal("Error: -keep-newest must be at least 1.")
 	}
-	// Determine if we have a file mask
-	_, err = os.Stat(filepath.Join(folder, fileName))
-	if err != nil {
-		// Test as a glob matcher.
-		files, err := filepath.Glob(filepath.Join(folder, fileName))
-		if err != nil {
-			fmt.Printf("Bad filename: Could not find nor use as a pattern %s in %s.\n", fileName, InputFileName)
-			return
+	if err := SetTagFilter(*pOnlyTags); err != nil {
+		Fatal("Error: %s", err.Error())
+	}
+	if err := secretDetectors.Configure(*pSecrets); err != nil {
+		Fatal("Error: %s", err.Error())
+	}
+	if err := piiDetectors.Configure(*pPII); err != nil {
+		Fatal("Error: %s", err.Error())
+	}
+	if err := LoadSuppressionSets(*pSuppressSets); err != nil {
+		Fatal("Error: %s", err.Error())
+	}
+	if newlineMode != "keep" && newlineMode != "split" && newlineMode != "escape" {
+		Fatal("Error: -newlines must be keep, split or escape, not %s.", *pNewlines)
+	}
+	if !slices.Contains(escapeModes, escapeMode) {
+		Fatal("Error: -escape must be one of %s, not %s.", strings.Join(escapeModes, ", "), *pEscape)
+	}
+	if maxLengthMode != "split" && maxLengthMode != "truncate" && maxLengthMode != "drop" {
+		Fatal("Error: -max-len-mode must be split, truncate or drop, not %s.", *pMaxLenMode)
+	}
+	if minWordRatio > 0 || minDictWords > 0 {
+		if err := LoadDictionary(*pDictLang, *pDictFiles); err != nil {
+			Fatal("Error: Could not load word lists: %s", err.Error())
 		}
-		if len(files) == 0 && !*pRecurseDirs {
-			fmt.Printf("No files matching %s in %s found.\n", fileName, folder)
-			return
+	}
+
+	if len(*pBoilerplateIn) > 0 {
+		if err := LoadBoilerplate(*pBoilerplateIn); err != nil {
+			Fatal("Error: Could not load boilerplate: %s", err.Error())
 		}
 	}
-	if len(*pSearchList) > *pMinLen {
-		searchStringsList = strings.Split(strings.ToUpper(*pSearchList), ",")
+	if versionAcrossDirs && VersionSelectionActive(*pSkipOlderMatch) {
+		collectingVersions = true
+		ScanInputs(inputs, listedPaths, *pRecurseDirs, minStr, *putf8, *putf16, *pAlphaRatio, *pSkipOlderMatch)
+		collectingVersions = false
+		SelectCollectedVersions()
+		excludedFileNames = nil // The real pass excludes them again.
 	}
-	if len(*pSuppressList) > *pMinLen {
-		suppressList = strings.Split(strings.ToUpper(*pSuppressList), ",")
+	if *pLearnBoilerplate > 0 {
+		learningPass = true
+		ScanInputs(inputs, listedPaths, *pRecurseDirs, minStr, *putf8, *putf16, *pAlphaRatio, *pSkipOlderMatch)
+		learningPass = false
+		learned := LearnBoilerplate(*pLearnBoilerplate)
+		if debugOutput {
+			Debugf("Learned %d boilerplate strings from %d files.\n", learned, learnedFileCount)
+		}
+		// The real pass starts over.
+		includedFileNames = nil
+		excludedFileNames = nil
+		stringCount = 0
+		utf16StringCount = 0
+		if len(*pBoilerplateOut) > 0 {
+			if err := SaveBoilerplate(*pBoilerplateOut); err != nil {
+				ReportError("File Write Error to %s: %s", *pBoilerplateOut, err.Error())
+			}
+		}
 	}
 
-	directoriesProcessed, filesProcessed := RecurseDirectories(folder, *pRecurseDirs, fileName, minStr, *putf8, *putf16, *pAlphaRatio, *pSkipOlderMatch)
+	directoriesProcessed, filesProcessed := ScanInputs(inputs, listedPaths, *pRecurseDirs, minStr, *putf8, *putf16, *pAlphaRatio, *pSkipOlderMatch)
 	if debugOutput {
-		fmt.Printf("Processed %d directories.\n", directoriesProcessed)
-		fmt.Printf("Processed %d files:\n", filesProcessed)
+		Debugf("Processed %d directories.\n", directoriesProcessed)
+		Debugf("Processed %d files:\n", filesProcessed)
 		for _, fname := range includedFileNames {
-			fmt.Println(fname)
+			Debugf("%s\n", fname)
 		}
 		if len(excludedFileNames) > 0 {
-			fmt.Println("Excluded Files:")
+			Debugf("Excluded Files:\n")
 			for _, fname := range excludedFileNames {
-				fmt.Println(fname)
+				Debugf("%s\n", fname)
 			}
 		} else {
-			fmt.Println("No files excluded.")
+			Debugf("No files excluded.\n")
 		}
 
-		fmt.Printf("Found %d ASCII/UTF-8 strings and %d UTF-16 strings.", stringCount, utf16StringCount)
+		Debugf("Found %d ASCII/UTF-8 strings and %d UTF-16 strings.\n", stringCount, utf16StringCount)
 	}
+	PrintErrorSummary()
+	os.Exit(ExitStatus())
 }
 
 // / <summary>Recurse through directories to process (ASCII-fy) all files.</summary>
@@ -308,46 +529,97 @@ func main() {
 // / <param name="skipOlderMatch">Pass-Through, used to only grab newest matching file.</param>
 // / <returns></returns>
 func RecurseDirectories(folder string, recurse bool, fileMask string, minLen int, utf8 bool, utf16 bool, alphaRatio int, skipOlderMatch string) (dirCount int, fileCount int) {
+	if !EnterDirectory(folder) {
+		return 0, 0
+	}
 	dirCount = 1
-	dirs, files := filesInDirectory(folder, fileMask, SORTBY_DATE, false)
+	dirs, files := filesInDirectory(folder, fileMask, sortBy, sortAscending)
 
-	baseNames = nil // File matching is per-directory
+	previousRules := LoadIgnoreFiles(folder)
+	var candidates []string
 	for _, file := range files {
-		fileHandled := false
-		if !noExpansion {
-			pArchive, _ := archiver.GetArchiveInfo(filepath.Join(folder, file))
-			if pArchive != nil && pArchive.ArchiveType > archiver.ARCHIVE_NA {
-				// Handle binary bits.  Trouble is... as a file.
-				for _, compressedFile := range pArchive.Files() {
-					fileContent, err := compressedFile.GetBytes()
-					if err != nil {
-						if writeVerbose {
-							fmt.Printf("Decompression error in %s / %s: %s\n", compressedFile.Path(), compressedFile.Name(), err.Error())
-						}
-					} else if len(fileContent) > minLen {
-						asciifyBlob(folder, file+"-"+compressedFile.Name(), fileContent, minLen, utf8, alphaRatio, skipOlderMatch, utf16)
-						fileHandled = true
-					}
-				}
-			}
-		}
-		if !fileHandled { // Don't examine binary archives that we've checked inside.
-			AsciifyFile(folder, file, minLen, utf8, alphaRatio, skipOlderMatch, utf16)
+		if !PassesPathFilter(folder, file) || IsIgnored(folder, file, false) {
+			excludedFileNames = append(excludedFileNames, filepath.Join(folder, file))
+		} else if IsReadable(folder, file) { // Before version selection, so a skipped file can't be the newest.
+			candidates = append(candidates, file)
 		}
+	}
+	if collectingVersions {
+		CollectVersions(folder, candidates, skipOlderMatch)
+		candidates = nil
+	}
+	for _, file := range SelectVersions(folder, candidates, skipOlderMatch) {
+		ProcessFile(folder, file, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
 		fileCount++
 	}
 
 	if recurse {
 		for _, dir := range dirs {
+			if PruneDirectory(folder, dir) || IsIgnored(folder, dir, true) {
+				if debugOutput {
+					Debugf("Skipping directory %s\n", filepath.Join(folder, dir))
+				}
+				continue
+			}
 			newDirs, newFiles := RecurseDirectories(filepath.Join(folder, dir), recurse, fileMask, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
 			dirCount += newDirs
 			fileCount += newFiles
 		}
 	}
+	ignoreRules = ignoreRules[:previousRules]
 
 	return dirCount, fileCount
 }
 
+// / <summary>Is a file to be read: not our own output, and passing the size, time and type filters?</summary>
+func IsReadable(folder string, file string) bool {
+	if IsOutputManifest(folder, file) {
+		excludedFileNames = append(excludedFileNames, filepath.Join(folder, file))
+		return false
+	}
+	if IsOwnOutput(folder, file) {
+		SkipFile(filepath.Join(folder, file), "our own output, listed in "+outputManifestName+".  (-scan-outputs reads it.)")
+		return false
+	}
+	if !PassesFileFilters(folder, file) {
+		excludedFileNames = append(excludedFileNames, filepath.Join(folder, file))
+		return false
+	}
+	return true
+}
+
+// / <summary>Processes one file: each file inside it if it's an archive, otherwise the file itself.</summary>
+func ProcessFile(folder string, file string, minLen int, utf8 bool, utf16 bool, alphaRatio int, skipOlderMatch string) {
+	if !noExpansion && isRegularFile(filepath.Join(folder, file)) { // Sniffing a pipe would use up what it's read.
+		pArchive, _ := archiver.GetArchiveInfo(filepath.Join(folder, file))
+		if pArchive != nil && pArchive.ArchiveType > archiver.ARCHIVE_NA {
+			fileHandled := false
+			// Handle binary bits.  Trouble is... as a file.
+			for _, compressedFile := range pArchive.Files() {
+				fileContent, err := compressedFile.GetBytes()
+				if err != nil {
+					ReportError("Decompression error in %s / %s: %s", compressedFile.Path(), compressedFile.Name(), err.Error())
+				} else if len(fileContent) > minLen {
+					asciifyBlob(folder, file, compressedFile.Name(), fileContent, minLen, utf8, alphaRatio, skipOlderMatch, utf16)
+					fileHandled = true
+				}
+			}
+			if fileHandled { // Don't examine binary archives that we've checked inside.
+				return
+			}
+		}
+	}
+	AsciifyFile(folder, file, minLen, utf8, alphaRatio, skipOlderMatch, utf16)
+}
+
+// Notes a file not read, saying why with -v or -d.  (Once: not in the first pass of a two-pass scan.)
+func SkipFile(fullFileName string, reason string) {
+	excludedFileNames = append(excludedFileNames, fullFileName)
+	if (writeVerbose || debugOutput) && !learningPass && !collectingVersions {
+		Debugf("Skipping %s: %s\n", fullFileName, reason)
+	}
+}
+
 func MatchStartUpdate(curMatch int, curFileIndex int) int {
 	if curMatch == -1 {
 		return curFileIndex
@@ -358,13 +630,9 @@ func MatchStartUpdate(curMatch int, curFileIndex int) int {
 // / <summary>
 // / Extract ASCII or UTF8 data from one file.
 // / </summary>
-// / <returns>Was a file processed?  (False if it couldn't be opened or was redundant.)</returns>
+// / <returns>Was a file processed?  (False if it couldn't be opened.)</returns>
 func AsciifyFile(folder string, file string, minimumMatchLength int, utf8Mode bool, alphaRatio int, oldMatchString string, utf16Mode bool) bool {
 	fullFileName := filepath.Join(folder, file)
-	if !PassesFileMatch(file, oldMatchString) { // Have we seen this basefile before?
-		excludedFileNames = append(excludedFileNames, fullFileName)
-		return false
-	}
 	includedFileNames = append(includedFileNames, fullFileName)
 	if writeVerbose {
 		fmt.Printf("File: %s in Folder: %s\n", file, folder)
@@ -372,25 +640,50 @@ func AsciifyFile(folder string, file string, minimumMatchLength int, utf8Mode bo
 
 	fileContents, err := os.ReadFile(fullFileName)
 	if err != nil {
-		fmt.Printf("ERROR: %s / %s: %s\n", folder, file, err.Error())
+		ReportError("ERROR: %s / %s: %s", folder, file, err.Error())
 		return false
 	}
-	return asciifyBlob(folder, file, fileContents, minimumMatchLength, utf8Mode, alphaRatio, oldMatchString, utf16Mode)
+	return asciifyBlob(folder, file, "", fileContents, minimumMatchLength, utf8Mode, alphaRatio, oldMatchString, utf16Mode)
 }
 
-func asciifyBlob(folder string, file string, fileContents []byte, minimumMatchLength int, utf8Mode bool, alphaRatio int, oldMatchString string, utf16Mode bool) bool {
-	SepChar := "\n"
+// / <summary>Extract ASCII or UTF8 data from file contents, and output it.</summary>
+// / <param name="member">If the contents are from inside an archive, the name of the file in the archive.</param>
+func asciifyBlob(folder string, file string, member string, fileContents []byte, minimumMatchLength int, utf8Mode bool, alphaRatio int, oldMatchString string, utf16Mode bool) bool {
+	displayName := DisplayName(folder, file, member)
+	sourcePath := filepath.Join(folder, file)
+	if len(member) > 0 {
+		file += "-" + member // For the output file name.
+	}
+	SepChar := recordSeparator
 	workString := ""
-	resultString := ""
+	var workSizes []uint8
+	var found []foundString
 
 	fileIndex := 0   // Tracks current position of pointer
 	matchStart := -1 // -1 when not in a string.  Current first char index when in one.
 	isCharacterValid := false
 	stringHasUTF16 := false
 	newChar := ""
+	var pending *foundString // -join-gap: the last run, waiting to see if the next is close enough to join.
+	vetRun := func(run foundString) {
+		if secretDetectors.redact || piiDetectors.redact {
+			run.redactions = RedactDetections(run.text)
+			for i := range run.redactions { // Each is counted once, however many pieces it's split into.
+				run.redactions[i].counted = new(bool)
+			}
+		}
+		for _, line := range SplitLines(run) {
+			for _, piece := range SplitFound(line) {
+				if VetString(piece.text, minimumMatchLength, alphaRatio) {
+					found = append(found, piece)
+				}
+			}
+		}
+	}
 
 	for fileIndex < len(fileContents) {
 		foundChar := false
+		wide := false // A UTF-16 string, rather than one character.
 		newIndex := -1
 		// Try UTF16 first, if enabled, because safer on the index.  This call checks for minLen.
 		if utf16Mode {
@@ -402,10 +695,13 @@ func asciifyBlob(folder string, file string, fileContents []byte, minimumMatchLe
 				}
 				foundChar = true
 				stringHasUTF16 = true
+				wide = true
 			}
 		}
 
-		if !foundChar {
+		if !foundChar && wideOnly {
+			newIndex = fileIndex + 1
+		} else if !foundChar {
 			isCharacterValid, newChar, newIndex = GetChar(fileContents, fileIndex, utf8Mode)
 			if isCharacterValid {
 				foundChar = true
@@ -414,70 +710,374 @@ func asciifyBlob(folder string, file string, fileContents []byte, minimumMatchLe
 		// The logic here is, UTF16 will grab the entire string at once, so it needs to be closed off.
 		if foundChar { // && !stringHasUTF16 {
 			if newIndex == -1 {
-				fmt.Println("Error: New index wrong.")
-				os.Exit(1)
+				Fatal("Error: New index wrong.")
 			}
-			fileIndex = newIndex
 			matchStart = MatchStartUpdate(matchStart, fileIndex)
+			workSizes = append(workSizes, textSizes(newChar, newIndex-fileIndex, wide)...)
+			fileIndex = newIndex
 			workString += newChar
 		}
-		if !foundChar || fileIndex+1 == len(fileContents) {
+		if !foundChar || fileIndex >= len(fileContents) {
 			// Char was Invalid or EOF - Check to see if we should write string
-			if VetString(workString, minimumMatchLength, alphaRatio) {
-				if writeOffset {
-					resultString += fmt.Sprintf("%08X: ", matchStart)
-				}
-				resultString += workString + SepChar
-				stringCount++
-				if stringHasUTF16 {
-					utf16StringCount++
-					stringHasUTF16 = false
+			if len(workString) > 0 {
+				run := foundString{offset: matchStart, text: workString, sizes: workSizes, utf16: stringHasUTF16}
+				if joinGap <= 0 {
+					vetRun(run)
+				} else if pending != nil && pending.utf16 == run.utf16 && run.offset-pending.end() <= joinGap {
+					pending.Join(run)
+				} else {
+					if pending != nil {
+						vetRun(*pending)
+					}
+					pending = &run
 				}
 			}
+			stringHasUTF16 = false
 			fileIndex = newIndex
 			workString = ""
+			workSizes = nil
 			matchStart = -1
 		}
 	}
 
+	if pending != nil {
+		vetRun(*pending)
+	}
+
+	if learningPass {
+		learnedStrings := make(map[string]bool)
+		for _, f := range found {
+			learnedStrings[strings.ToUpper(f.text)] = true
+		}
+		CountFileStrings(learnedStrings)
+		return true
+	}
+	hitCount := stringCount
+	resultString := FormatFoundStrings(displayName, fileContents, found, SepChar)
+	hitCount = stringCount - hitCount
+
 	// string ascii = System.Text.ASCIIEncoding.ASCII.GetString(dest.ToArray<byte>());
-	if (!writeFiles) || (writeVerbose) {
-		fmt.Println(resultString)
+	if listFiles {
+		if hitCount > 0 && !listedFiles[sourcePath] { // Once per file, however many archive members hit.
+			fmt.Println(DisplayName(folder, filepath.Base(sourcePath), ""))
+			listedFiles[sourcePath] = true
+		}
+	} else if countHits {
+		fmt.Printf("%s:%d\n", displayName, hitCount)
+	} else if !writeFiles || writeVerbose || len(folder) == 0 {
+		if SepChar == "\n" && !withFileName && !gnuStringsMode {
+			fmt.Println(resultString)
+		} else { // No extra line between files for -0, and none needed with file names.
+			fmt.Print(resultString)
+		}
 	}
 
-	if writeFiles {
+	if writeFiles && len(folder) > 0 { // Standard input has nowhere to write next to.
 		if len(writePath) == 0 {
-			err := os.WriteFile(filepath.Join(folder, file)+".txt", []byte(resultString), 0644)
+			err := WriteOutput(filepath.Join(folder, file)+".txt", resultString)
 			if err != nil {
-				fmt.Printf("File Write Error to %s - %s: %s\n", folder, file, err.Error())
+				ReportError("File Write Error to %s - %s: %s", folder, file, err.Error())
 			}
 		} else { //  Write to the specified path, flattened.
-			// Len + 1 to trim off Path.DirectorySeparatorChar from file name.
-			// TO DO: This should include the entire path from the starting location, with sep replaced.
-			// That is folder - startPath
-			newFileName := folder[len(startPath)+1:]
-			newFileName = strings.ReplaceAll(newFileName, string(filepath.Separator), "-") + "-" + file + ".txt"
+			// The path from the input's starting location, with separators replaced.
+			newFileName := file + ".txt"
+			if relative, err := filepath.Rel(startPath, folder); err == nil && relative != "." {
+				newFileName = strings.ReplaceAll(relative, string(filepath.Separator), "-") + "-" + newFileName
+			}
 			if debugOutput {
-				fmt.Printf("Writing %s to %s.\n", writePath, newFileName)
+				Debugf("Writing %s to %s.\n", writePath, newFileName)
 			}
-			err := os.WriteFile(filepath.Join(writePath, newFileName), []byte(resultString), 0644)
+			err := WriteOutput(filepath.Join(writePath, newFileName), resultString)
 
 			if err != nil {
-				fmt.Printf("File Write Error to %s - %s: %s\n", folder, file, err.Error())
+				ReportError("File Write Error to %s - %s: %s", folder, file, err.Error())
 			}
 		}
 	}
+	secretDetectors.Summarize(displayName)
+	piiDetectors.Summarize(displayName)
 	return true
 }
 
+// A string found in a file, and where.
+type foundString struct {
+	offset int
+	text   string
+	sizes  []uint8 // File bytes behind each byte of text.  Latin-1 and UTF-16 characters decode to more, or fewer.
+	utf16  bool
+	gaps   []textGap // Non-text bytes skipped by -join-gap.
+
+	redactions []detection // Redact mode: detections in the whole run, before -newlines split or -max-len split it.
+}
+
+type textGap struct {
+	offset int
+	length int
+}
+
+// File bytes behind each byte of decoded text: all of a character's on its first byte.
+// fileBytes is for one character, or a UTF-16 string's characters are wideCharBytes each.
+func textSizes(text string, fileBytes int, wide bool) []uint8 {
+	sizes := make([]uint8, len(text))
+	for i := range text { // Each character's first byte
+		if wide {
+			sizes[i] = uint8(wideCharBytes)
+		} else {
+			sizes[i] = uint8(fileBytes)
+			break
+		}
+	}
+	return sizes
+}
+
+// Bytes text[start:end] takes in the file, not counting gaps.
+func (f foundString) sourceBytes(start int, end int) int {
+	length := 0
+	for _, size := range f.sizes[start:end] {
+		length += int(size)
+	}
+	return length
+}
+
+// Length of the string in the file, including any gaps.
+func (f foundString) byteLength() int {
+	length := f.sourceBytes(0, len(f.text))
+	for _, gap := range f.gaps {
+		length += gap.length
+	}
+	return length
+}
+
+func (f foundString) end() int {
+	return f.offset + f.byteLength()
+}
+
+// Appends a following run, recording the gap between them.
+func (f *foundString) Join(run foundString) {
+	if gap := run.offset - f.end(); gap > 0 {
+		f.gaps = append(f.gaps, textGap{f.end(), gap})
+	}
+	f.gaps = append(f.gaps, run.gaps...)
+	f.text += run.text
+	f.sizes = append(f.sizes, run.sizes...)
+}
+
+// File offset of byte index in the text, allowing for gaps.
+func (f foundString) fileOffset(index int) int {
+	offset := f.offset + f.sourceBytes(0, index)
+	for _, gap := range f.gaps {
+		if gap.offset > offset {
+			break
+		}
+		offset += gap.length
+	}
+	return offset
+}
+
+// The part of the string from start to end (byte indices in the text), with its own offset and gaps.
+func (f foundString) Sub(start int, end int) foundString {
+	sub := foundString{offset: f.fileOffset(start), text: f.text[start:end], sizes: f.sizes[start:end], utf16: f.utf16}
+	if end > start {
+		endOffset := f.fileOffset(end-1) + f.sourceBytes(end-1, end)
+		for _, gap := range f.gaps {
+			if gap.offset > sub.offset && gap.offset < endOffset {
+				sub.gaps = append(sub.gaps, gap)
+			}
+		}
+	}
+	for _, d := range f.redactions { // The part of each in the piece.
+		if d.end > start && d.start < end {
+			sub.redactions = append(sub.redactions, detection{d.name, max(d.start, start) - start, min(d.end, end) - start, d.set, d.counted})
+		}
+	}
+	return sub
+}
+
+// / <summary>Applies -max-len to a found string, keeping track of where each piece is in the file.</summary>
+// / <returns>The pieces, each with the gaps inside it.</returns>
+func SplitFound(f foundString) []foundString {
+	pieces := LimitLength(f.text)
+	if len(pieces) == 1 && pieces[0] == f.text {
+		return []foundString{f}
+	}
+	var result []foundString
+	index := 0
+	for _, piece := range pieces {
+		result = append(result, f.Sub(index, index+len(piece)))
+		index += len(piece)
+	}
+	return result
+}
+
+// / <summary>For -newlines split: breaks a found string into its lines.</summary>
+// / <returns>The lines, without their CR/LFs.  Empty lines are dropped.</returns>
+func SplitLines(f foundString) []foundString {
+	if newlineMode != "split" || !strings.ContainsAny(f.text, "\r\n") {
+		return []foundString{f}
+	}
+	var lines []foundString
+	start := 0
+	for index := 0; index <= len(f.text); index++ {
+		if index == len(f.text) || f.text[index] == '\n' || f.text[index] == '\r' {
+			if index > start {
+				lines = append(lines, f.Sub(start, index))
+			}
+			start = index + 1
+		}
+	}
+	return lines
+}
+
+// / <summary>How a file, or a file in an archive, is named in output: path[:member].
+// / The path is relative to the current directory if it's under it.</summary>
+func DisplayName(folder string, file string, member string) string {
+	name := filepath.Join(folder, file)
+	if cwd, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(relative, "..") {
+			name = relative
+		}
+	}
+	if len(member) > 0 {
+		name += ":" + member
+	}
+	return name
+}
+
+// / <summary>Builds the output for one file: the strings that pass the search filter, with any context requested.</summary>
+// / <param name="displayName">For reports and -H.</param>
+// / <param name="fileContents">For hex context.</param>
+// / <param name="found">Every vetted string in the file, in order.</param>
+// / <param name="SepChar">Separator after each string.</param>
+// / <returns>Output text.</returns>
+func FormatFoundStrings(displayName string, fileContents []byte, found []foundString, SepChar string) string {
+	resultString := ""
+	hits := make([]string, len(found)) // Formatted output of each hit.  Empty if not a hit.
+	for i, f := range found {
+		if !PassesSearch(f.text) {
+			continue
+		}
+		if secretDetectors.report {
+			secretDetectors.Report(displayName, f)
+		}
+		if piiDetectors.report {
+			piiDetectors.Report(displayName, f)
+		}
+		if output, ok := FormatFoundString(f, false, displayName); ok {
+			hits[i] = output
+		}
+	}
+
+	dumpSource := RedactHexContext(fileContents, found)
+	lastShown := -1
+	for i, f := range found {
+		if len(hits[i]) == 0 {
+			if !InContext(hits, i) {
+				continue
+			}
+			output, _ := FormatFoundString(f, true, displayName)
+			resultString += output + SepChar
+		} else {
+			if contextStrings > 0 && lastShown >= 0 && i > lastShown+1 {
+				resultString += "--" + SepChar
+			}
+			resultString += hits[i] + SepChar
+			if hexContext > 0 {
+				resultString += HexDump(dumpSource, f.offset-hexContext, f.offset+f.byteLength()+hexContext)
+			}
+			stringCount++
+			if f.utf16 {
+				utf16StringCount++
+			}
+		}
+		lastShown = i
+	}
+	return resultString
+}
+
+// / <summary>Formats a vetted string for output, with its offset and tags as requested, secrets and PII redacted, and escaped.</summary>
+// / <param name="f">The string and where it was found.</param>
+// / <param name="context">Is this context around a hit, rather than a hit?  Marked grep-style, and not filtered.</param>
+// / <param name="displayName">File name for -H.</param>
+// / <returns>The output line, and false if the string is filtered out by its tags.</returns>
+func FormatFoundString(f foundString, context bool, displayName string) (string, bool) {
+	output := ""
+	src := f.text
+	if gnuStringsMode { // file: offset string
+		if withFileName {
+			output += displayName + ": "
+		}
+		if writeOffset {
+			output += fmt.Sprintf(stringsOffsetFormat+" ", f.offset)
+		}
+	} else if withFileName { // grep style, path:offset:string, or path-offset-string for context.
+		separator := IIF(context, "-", ":")
+		output += fmt.Sprintf("%s%s%08X%s", displayName, separator, f.offset, separator)
+	} else if writeOffset {
+		output += fmt.Sprintf("%08X", f.offset)
+		if len(f.gaps) > 0 {
+			var gaps []string
+			for _, gap := range f.gaps {
+				gaps = append(gaps, fmt.Sprintf("%08X+%d", gap.offset, gap.length))
+			}
+			output += " (gaps " + strings.Join(gaps, ",") + ")"
+		}
+		output += IIF(context, "- ", ": ")
+	}
+	if context {
+		// Shown as-is, apart from redaction.
+	} else if showTags || onlyAnyTag || len(onlyTags) > 0 {
+		tags := ClassifyString(src)
+		if !PassesTagFilter(tags) {
+			return "", false
+		}
+		if showTags && len(tags) > 0 {
+			output += "[" + strings.Join(tags, ",") + "] "
+		}
+	}
+	if secretDetectors.redact || piiDetectors.redact { // Including what was found before the string was split.
+		src = Redact(src, mergeDetections(append(RedactDetections(src), f.redactions...)))
+	}
+	if escapeMode != "raw" {
+		src = EscapeString(src, escapeMode)
+	} else if newlineMode == "escape" {
+		src = strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(src)
+	}
+	return output + src, true
+}
+
 // C# inline function equivalent
+// Default is (((b > 31) && (b < 127)) || (b == 9) || (b == 10)), see -charset.
 func isCharacterASCII(b byte) bool {
-	return (((b > 31) && (b < 127)) || (b == 9) || (b == 10))
+	return asciiCharset[b]
+}
+
+// / <summary>Applies -max-len to a string.</summary>
+// / <param name="src">String as found.</param>
+// / <returns>The string, split into pieces, truncated, or nothing if dropped.</returns>
+func LimitLength(src string) []string {
+	if maxLength <= 0 || utf8.RuneCountInString(src) <= maxLength {
+		return []string{src}
+	}
+	if maxLengthMode == "drop" {
+		return nil
+	}
+	var pieces []string
+	runes := []rune(src)
+	for len(runes) > maxLength {
+		pieces = append(pieces, string(runes[:maxLength]))
+		runes = runes[maxLength:]
+		if maxLengthMode == "truncate" {
+			return pieces
+		}
+	}
+	return append(pieces, string(runes))
 }
 
 // / <summary>
-// / Validates whether this string is acceptable: Is it long enough, and is it ASCII-enough.
+// / Validates whether this string is acceptable: Is it long enough (bytes, characters, words), and is it ASCII-enough.
 // / For the latter, counts alphanumeric, space, CR/LF, period and comma.
+// / Optionally also requires a minimum natural-language plausibility score and dictionary words.
+// / Suppressed strings fail.  The -f search is separate, in PassesSearch, so hits can be shown in context.
 // / </summary>
 // / <param name="src"></param>
 // / <param name="minLen"></param>
@@ -487,6 +1087,12 @@ func VetString(src string, minLen int, minRatio int) bool {
 	if len(src) < minLen {
 		return false
 	}
+	if minRunes > 0 && utf8.RuneCountInString(src) < minRunes {
+		return false
+	}
+	if minWords > 0 && len(strings.Fields(src)) < minWords {
+		return false
+	}
 	if minRatio > 0 { //  Count chars
 		asciiChars := 0
 		UTF8Chars := 0
@@ -506,16 +1112,30 @@ func VetString(src string, minLen int, minRatio int) bool {
 			return false
 		}
 	}
+	if minPlausibility > 0 && PlausibilityScore(src) < minPlausibility {
+		return false
+	}
+	if (minWordRatio > 0 || minDictWords > 0) && !PassesDictionary(src) {
+		return false
+	}
 
 	// Determine if string should be suppressed.
+	if len(learnedBoilerplate) > 0 && learnedBoilerplate[strings.ToUpper(src)] {
+		return false
+	}
 	if len(suppressList) > 0 {
 		testString := strings.ToUpper(src)
 		for _, s := range suppressList {
-			if testString == s {
+			if SuppressionMatch(testString, s) {
 				return false
 			}
 		}
 	}
+	return true
+}
+
+// Is the string in the -f list, if there is one?
+func PassesSearch(src string) bool {
 	if len(searchStringsList) > 0 { // Determine if strings qualify
 		testString := strings.ToUpper(src)
 		for _, s := range searchStringsList {
@@ -555,7 +1175,10 @@ func GetChar(src []byte, startIndex int, UTF8 bool) (bool, string, int) {
 	b := src[startIndex]
 	startIndex++ // ASCII always increments counter by 1.
 	// This check is true for first character always.
-	if isCharacterASCII(b) { // (((b > 31) && (b < 127)) || (b == 9) || (b == 10))   // Valid character
+	if isCharacterASCII(b) { // Valid character, per -charset
+		if b > 127 && !latin1HighBytes {
+			return true, string([]byte{b}), startIndex
+		}
 		chars += string(b)
 		return true, chars, startIndex
 	}
@@ -611,7 +1234,25 @@ func GetChar(src []byte, startIndex int, UTF8 bool) (bool, string, int) {
 	return true, chars, startIndex
 }
 
-// / <summary>Checks for UTF-16 sequence, BE only (i.e. ASCII - 00 pairs).  Expect a 00 00 terminator.</summary>
+// Reads one ASCII character in a wide encoding, per wideCharBytes and wideBigEndian.
+// Returns 0 if the bytes are not an ASCII character padded with zeros.
+func wideChar(src []byte) byte {
+	c := src[0]
+	padding := src[1:]
+	if wideBigEndian {
+		c = src[len(src)-1]
+		padding = src[:len(src)-1]
+	}
+	for _, b := range padding {
+		if b != 0 {
+			return 0
+		}
+	}
+	return c
+}
+
+// / <summary>Checks for UTF-16 sequence, LE by default (i.e. ASCII - 00 pairs).  Expect a 00 00 terminator unless wideTerminated is off.
+// / wideCharBytes and wideBigEndian allow UTF-16BE and UTF-32, for GNU strings -e.</summary>
 // / <param name="src">bytes to find string in</param>
 // / <param name="startIndex">current starting point; this is updated on return.</param>
 // / <param name="minLen">String must be this long to qualify</param>
@@ -620,17 +1261,20 @@ func GetUTF16String(src []byte, index int, minLen int) (bool, string, int) {
 	foundString := ""
 	success := true
 	for success {
-		if index+2 > len(src) { // EOF
-			success = false
+		if index+wideCharBytes > len(src) { // EOF
+			success = !wideTerminated
 			break
 		}
-		if (isCharacterASCII(src[index])) && (src[index+1] == 0) { // Valid ASCII
-			foundString += string(src[index])
-			index += 2
+		c := wideChar(src[index : index+wideCharBytes])
+		if c != 0 && isCharacterASCII(c) { // Valid ASCII
+			foundString += string(c)
+			index += wideCharBytes
 			continue
-		} else if (len(foundString) > 1) && (src[index] == 0) && (src[index+1] == 0) { // Null terminator
+		} else if wideTerminated && (len(foundString) > 1) && c == 0 && src[index] == 0 && src[index+wideCharBytes-1] == 0 { // Null terminator
 			foundString = strings.TrimSpace(foundString)
 			break
+		} else if !wideTerminated {
+			break // Any non-character ends it.
 		}
 		success = false // Failure.
 	}
@@ -643,21 +1287,3 @@ func GetUTF16String(src []byte, index int, minLen int) (bool, string, int) {
 
 	return success, foundString, index // Some stuff, perhaps PDFs, fall through here with a ton of short codes and \n.
 }
-
-// If we are in dated-file-avoidance-mode, is this file new?
-// skipString is the <param name="fname">File name we're checking</param>
-// <param name="skipString">Where the date or incrementor starts</param>
-// <returns>True if this is the first of its kind.</returns>
-func PassesFileMatch(fname string, skipString string) bool {
-	if len(skipString) == 0 {
-		return true
-	}
-	skipIndex := strings.Index(fname, skipString)
-
-	checkName := IIF(skipIndex >= 0, fname[:skipIndex], fname)
-	if slices.Contains(baseNames, checkName) {
-		return false
-	}
-	baseNames = append(baseNames, checkName)
-	return true
-}
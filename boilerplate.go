@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Corpus-learned boilerplate suppression.
+// When scanning many files from one application, the same strings turn up in nearly all of them.
+// A first pass over the files counts how many files each string occurs in, and strings in more than
+// the given percentage of files are suppressed during the real pass.  The learned list can be saved
+// and loaded in later runs, one Go-quoted string per line.
+
+var (
+	learningPass       = false         // True during the counting pass: strings are counted, not output.
+	learnedCounts      map[string]int  // Upper-cased string -> number of files it was found in.
+	learnedFileCount   = 0             // Files (and archive members) seen in the counting pass.
+	learnedBoilerplate map[string]bool // Upper-cased strings to suppress.
+)
+
+// Records the distinct strings of one file during the counting pass.
+func CountFileStrings(found map[string]bool) {
+	if learnedCounts == nil {
+		learnedCounts = make(map[string]int)
+	}
+	for s := range found {
+		learnedCounts[s]++
+	}
+	learnedFileCount++
+}
+
+// / <summary>Turns the counting pass into the boilerplate list.</summary>
+// / <param name="percent">Strings in more than this percentage of files are boilerplate.</param>
+// / <returns>How many strings were learned.</returns>
+func LearnBoilerplate(percent int) int {
+	if learnedBoilerplate == nil {
+		learnedBoilerplate = make(map[string]bool)
+	}
+	learned := 0
+	for s, count := range learnedCounts {
+		// A string in only one file isn't boilerplate, however few files there are.
+		if count > 1 && count*100 > percent*learnedFileCount {
+			learnedBoilerplate[s] = true
+			learned++
+		}
+	}
+	learnedCounts = nil
+	return learned
+}
+
+func SaveBoilerplate(fileName string) error {
+	var lines []string
+	for s := range learnedBoilerplate {
+		lines = append(lines, strconv.Quote(s))
+	}
+	slices.Sort(lines)
+	return os.WriteFile(fileName, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func LoadBoilerplate(fileName string) error {
+	pFile, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer pFile.Close()
+	if learnedBoilerplate == nil {
+		learnedBoilerplate = make(map[string]bool)
+	}
+	scanner := bufio.NewScanner(pFile)
+	scanner.Buffer(nil, 16*1024*1024) // Long strings are possible.
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		s, err := strconv.Unquote(line)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %s", fileName, lineNumber, err.Error())
+		}
+		learnedBoilerplate[strings.ToUpper(s)] = true
+	}
+	return scanner.Err()
+}
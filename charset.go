@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Configurable character classes for ASCII mode (and the ASCII-ish UTF-16 search).
+// The default is the original hard-coded set: 0x20-0x7E, tab and LF.
+//
+// A -charset spec is a comma-delimited list of items, each a named class, a byte, or a range of bytes.
+// Bytes are 0xNN, decimal, or a literal character.  Ranges are first-last, e.g. 0x20-0x7E or a-z.
+// Prefix items with + or - to add to or remove from the current set.  If the first item has no prefix,
+// the set starts empty; otherwise it starts from the default.
+//   "+cr,+ff"          default plus CR and form feed
+//   "-lf"              default without LF, so strings don't span lines
+//   "print,tab,cr,lf"  printable, tab, CR and LF
+//   "alnum,space"      letters, digits and spaces only
+
+type byteClass [256]bool
+
+var asciiCharset = namedByteClasses["default"]
+
+func byteRange(first, last byte) (class byteClass) {
+	for b := int(first); b <= int(last); b++ {
+		class[b] = true
+	}
+	return class
+}
+
+func byteList(bytes ...byte) (class byteClass) {
+	for _, b := range bytes {
+		class[b] = true
+	}
+	return class
+}
+
+func (class byteClass) union(other byteClass) byteClass {
+	for b, in := range other {
+		class[b] = class[b] || in
+	}
+	return class
+}
+
+var namedByteClasses = map[string]byteClass{
+	"default":    byteRange(0x20, 0x7E).union(byteList('\t', '\n')),
+	"print":      byteRange(0x20, 0x7E),
+	"space":      byteList(' '),
+	"tab":        byteList('\t'),
+	"lf":         byteList('\n'),
+	"cr":         byteList('\r'),
+	"ff":         byteList('\f'),
+	"vt":         byteList('\v'),
+	"esc":        byteList(0x1B),
+	"nul":        byteList(0),
+	"bel":        byteList(7),
+	"bs":         byteList(8),
+	"del":        byteList(0x7F),
+	"whitespace": byteList(' ', '\t', '\n', '\v', '\f', '\r'),
+	"control":    byteRange(0, 0x1F).union(byteList(0x7F)),
+	"upper":      byteRange('A', 'Z'),
+	"lower":      byteRange('a', 'z'),
+	"alpha":      byteRange('A', 'Z').union(byteRange('a', 'z')),
+	"digit":      byteRange('0', '9'),
+	"alnum":      byteRange('A', 'Z').union(byteRange('a', 'z')).union(byteRange('0', '9')),
+	"punct":      byteRange('!', '/').union(byteRange(':', '@')).union(byteRange('[', '`')).union(byteRange('{', '~')),
+	"high":       byteRange(0x80, 0xFF),
+	"latin1":     byteRange(0xA0, 0xFF),
+}
+
+// One byte: 0xNN, decimal or a literal character.
+func parseCharsetByte(spec string) (byte, error) {
+	if len(spec) == 1 {
+		return spec[0], nil
+	}
+	value, err := strconv.ParseUint(spec, 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("bad byte %q in -charset", spec)
+	}
+	return byte(value), nil
+}
+
+// One item, without its +/- prefix: a named class, byte or range.
+func parseCharsetItem(item string) (byteClass, error) {
+	if class, found := namedByteClasses[strings.ToLower(item)]; found {
+		return class, nil
+	}
+	if first, last, found := strings.Cut(item[1:], "-"); found && len(last) > 0 { // item[1:] allows "--0x2F"
+		firstByte, err := parseCharsetByte(item[:1] + first)
+		if err != nil {
+			return byteClass{}, err
+		}
+		lastByte, err := parseCharsetByte(last)
+		if err != nil {
+			return byteClass{}, err
+		}
+		if lastByte < firstByte {
+			return byteClass{}, fmt.Errorf("backwards range %q in -charset", item)
+		}
+		return byteRange(firstByte, lastByte), nil
+	}
+	b, err := parseCharsetByte(item)
+	if err != nil {
+		return byteClass{}, fmt.Errorf("unknown class or byte %q in -charset", item)
+	}
+	return byteList(b), nil
+}
+
+// / <summary>Parses a -charset spec, as described above.</summary>
+// / <param name="spec">The spec.  Empty is the default set.</param>
+// / <returns>The set of bytes accepted as characters.</returns>
+func ParseCharset(spec string) (byteClass, error) {
+	charset := namedByteClasses["default"]
+	if len(spec) == 0 {
+		return charset, nil
+	}
+	if spec[0] != '+' && spec[0] != '-' {
+		charset = byteClass{}
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		exclude := false
+		if len(item) > 1 && (item[0] == '+' || item[0] == '-') {
+			exclude = item[0] == '-'
+			item = item[1:]
+		}
+		if len(item) == 0 {
+			continue
+		}
+		class, err := parseCharsetItem(item)
+		if err != nil {
+			return charset, err
+		}
+		for b, in := range class {
+			if in {
+				charset[b] = !exclude
+			}
+		}
+	}
+	return charset, nil
+}
+
+func CharsetClassNames() []string {
+	var names []string
+	for name := range namedByteClasses {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Context around search hits, so a hit can be judged without opening a hex editor:
+// neighbouring strings from the same file (-C), and a hex dump of the surrounding bytes (-hex-context).
+
+var (
+	contextStrings = 0 // Strings to show before and after each hit.
+	hexContext     = 0 // Bytes to dump either side of each hit.
+)
+
+// Is string i within contextStrings of a hit?  hits holds the formatted hits, empty for non-hits.
+func InContext(hits []string, i int) bool {
+	if contextStrings <= 0 || len(searchStringsList) == 0 {
+		return false
+	}
+	for j := max(0, i-contextStrings); j <= min(len(hits)-1, i+contextStrings); j++ {
+		if len(hits[j]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// / <summary>hexdump -C style dump of part of a file.</summary>
+// / <param name="src">File contents.</param>
+// / <param name="start">First byte to dump.  Clipped to the file.</param>
+// / <param name="end">Byte after the last to dump.  Clipped to the file.</param>
+// / <returns>The dump, one line per 16 bytes, each line aligned to the 16s of the file.</returns>
+func HexDump(src []byte, start int, end int) string {
+	start = max(start, 0)
+	end = min(end, len(src))
+	var dump strings.Builder
+	for line := start - start%16; line < end; line += 16 {
+		hex := ""
+		text := ""
+		for i := line; i < line+16; i++ {
+			if i == line+8 {
+				hex += " "
+			}
+			if i < start || i >= end {
+				hex += "   "
+				text += " "
+				continue
+			}
+			hex += fmt.Sprintf("%02x ", src[i])
+			if src[i] > 31 && src[i] < 127 {
+				text += string(src[i])
+			} else {
+				text += "."
+			}
+		}
+		fmt.Fprintf(&dump, "  %08X  %s |%s|\n", line, hex, text)
+	}
+	return dump.String()
+}
+
+const redactedByte = '*' // What hex context shows for redacted bytes.
+
+// / <summary>Masks what -secrets/-pii redact mode hides from the text output in the bytes hex context dumps,
+// / so the dump doesn't give it away.  Exactly what's redacted from the found strings, mapped back to the file.</summary>
+// / <returns>src, or a masked copy of it.</returns>
+func RedactHexContext(src []byte, found []foundString) []byte {
+	if hexContext <= 0 || (!secretDetectors.redact && !piiDetectors.redact) {
+		return src
+	}
+	masked := slices.Clone(src)
+	mask := func(start int, end int) {
+		for i := max(start, 0); i < min(end, len(masked)); i++ {
+			masked[i] = redactedByte
+		}
+	}
+	for _, f := range found {
+		for _, d := range append(RedactDetections(f.text), f.redactions...) {
+			mask(f.fileOffset(d.start), f.fileOffset(d.end))
+		}
+	}
+	return masked
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Diagnostics and exit status.
+// Errors, warnings and debug output go to stderr, so stdout is only found strings and reports.
+// The exit status is grep's: 0 if anything was found, 1 if not, 2 if there were errors.
+
+const EXIT_FOUND = 0
+const EXIT_NOTHING_FOUND = 1
+const EXIT_ERROR = 2
+
+var errorList []string // Errors so far, for the end-of-run summary.
+
+// Reports an error that doesn't stop the run.  It's remembered for the summary and exit status.
+// Not during the -learn-boilerplate and -version-across-dirs first passes: the real pass reports the same errors.
+func ReportError(format string, args ...any) {
+	if learningPass || collectingVersions {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintln(os.Stderr, message)
+	errorList = append(errorList, message)
+}
+
+// Reports an error that stops the run, and exits.
+func Fatal(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(EXIT_ERROR)
+}
+
+// Debug output (-d).
+func Debugf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// Lists the errors again at the end, where they can't scroll past unseen.
+func PrintErrorSummary() {
+	if len(errorList) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%d error%s:\n", len(errorList), IIF(len(errorList) == 1, "", "s"))
+	for _, message := range errorList {
+		fmt.Fprintf(os.Stderr, "  %s\n", message)
+	}
+}
+
+func ExitStatus() int {
+	if len(errorList) > 0 {
+		return EXIT_ERROR
+	}
+	if stringCount > 0 {
+		return EXIT_FOUND
+	}
+	return EXIT_NOTHING_FOUND
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Dictionary word filtering.
+// Tokenizes found strings and requires some of the tokens to be real words, which tells
+// "the quick brown fox" from "aaaaaa bbbbbb" where -alpha-ratio can't.
+// Built-in lists are by language code (wordlists/<lang>.txt); users can add their own lists.
+// Each has about 1000 common words and forms; fuller or specialist lists can be added with -dict lang=file.
+// French elisions (l', d', qu') are in the French list, so l'homme counts by its prefix like company's does.
+
+//go:embed wordlists/*.txt
+var wordListFiles embed.FS
+
+var (
+	minWordRatio    = 0 // Percentage of tokens that must be dictionary words.  0 disables.
+	minDictWords    = 0 // Count of tokens that must be dictionary words.  0 disables.
+	dictionaryWords map[string]bool
+)
+
+// Adds one word per line to dictionaryWords.  Blank lines and # comments are skipped.
+func addWordList(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if len(word) == 0 || strings.HasPrefix(word, "#") {
+			continue
+		}
+		dictionaryWords[strings.ToLower(word)] = true
+	}
+}
+
+// / <summary>Builds the dictionary from the selected built-in languages and any custom lists.</summary>
+// / <param name="languages">Comma-delimited built-in language codes, e.g. "en,de".</param>
+// / <param name="customLists">Comma-delimited word list files.  "lang=path" only loads the file when lang is selected.</param>
+// / <returns>Error if a language or file can't be loaded.</returns>
+func LoadDictionary(languages string, customLists string) error {
+	dictionaryWords = make(map[string]bool)
+	selected := make(map[string]bool)
+	for _, lang := range strings.Split(languages, ",") {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if len(lang) == 0 {
+			continue
+		}
+		selected[lang] = true
+		pFile, err := wordListFiles.Open("wordlists/" + lang + ".txt")
+		if err != nil {
+			return fmt.Errorf("no built-in word list for language %q", lang)
+		}
+		addWordList(bufio.NewScanner(pFile))
+		pFile.Close()
+	}
+	for _, listFile := range strings.Split(customLists, ",") {
+		listFile = strings.TrimSpace(listFile)
+		if len(listFile) == 0 {
+			continue
+		}
+		if lang, path, found := strings.Cut(listFile, "="); found {
+			if !selected[strings.ToLower(lang)] {
+				continue
+			}
+			listFile = path
+		}
+		pFile, err := os.Open(listFile)
+		if err != nil {
+			return err
+		}
+		addWordList(bufio.NewScanner(pFile))
+		pFile.Close()
+	}
+	return nil
+}
+
+// Splits text into lower-case word tokens: runs of letters, allowing inner apostrophes.
+func wordTokens(text string) []string {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	var words []string
+	for _, token := range tokens {
+		token = strings.Trim(token, "'")
+		if len(token) > 0 {
+			words = append(words, token)
+		}
+	}
+	return words
+}
+
+// Does the text have enough dictionary words, by ratio and count?
+func PassesDictionary(text string) bool {
+	tokens := wordTokens(text)
+	known := 0
+	for _, token := range tokens {
+		if dictionaryWords[token] {
+			known++
+		} else if base, _, found := strings.Cut(token, "'"); found && dictionaryWords[base] {
+			known++ // Possessives and contractions: "company's", "don't"
+		}
+	}
+	if known < minDictWords {
+		return false
+	}
+	if minWordRatio > 0 && (len(tokens) == 0 || known*100/len(tokens) < minWordRatio) {
+		return false
+	}
+	return true
+}
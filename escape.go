@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Output escaping for found strings, so non-printable and non-ASCII content can't corrupt terminals
+// or trip up downstream tools.
+//   raw:   as found
+//   c:     C string escapes, \ooo octal for other control and non-ASCII bytes
+//   json:  JSON string escapes (without the quotes); invalid UTF-8 becomes �
+//   ascii: as json, plus \uXXXX for every non-ASCII character, including \uFFFD for invalid UTF-8, so the output is pure ASCII
+
+var escapeMode = "raw"
+
+var escapeModes = []string{"raw", "c", "json", "ascii"}
+
+// Escapes shared by C and JSON.
+var commonEscapes = map[rune]string{
+	'\\': `\\`,
+	'"':  `\"`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\b': `\b`,
+	'\f': `\f`,
+}
+
+func EscapeString(src string, mode string) string {
+	switch mode {
+	case "c":
+		return escapeC(src)
+	case "json", "ascii":
+		return escapeJSON(src, mode == "ascii")
+	}
+	return src
+}
+
+func escapeC(src string) string {
+	var out strings.Builder
+	for i := 0; i < len(src); i++ {
+		b := src[i]
+		if escape, found := commonEscapes[rune(b)]; found {
+			out.WriteString(escape)
+		} else if b == '\a' {
+			out.WriteString(`\a`)
+		} else if b == '\v' {
+			out.WriteString(`\v`)
+		} else if b < 0x20 || b >= 0x7F {
+			// Always three digits, so a following hex digit can't be taken as part of the escape.
+			fmt.Fprintf(&out, `\%03o`, b)
+		} else {
+			out.WriteByte(b)
+		}
+	}
+	return out.String()
+}
+
+func escapeJSON(src string, asciiOnly bool) string {
+	var out strings.Builder
+	for len(src) > 0 {
+		r, size := utf8.DecodeRuneInString(src)
+		src = src[size:]
+		if escape, found := commonEscapes[r]; found {
+			out.WriteString(escape)
+		} else if r < 0x20 || r == 0x7F || r == 0x2028 || r == 0x2029 {
+			fmt.Fprintf(&out, `\u%04X`, r)
+		} else if r == utf8.RuneError && size == 1 { // Invalid UTF-8
+			out.WriteString(IIF(asciiOnly, `\uFFFD`, `�`))
+		} else if asciiOnly && r > 0x7F {
+			if r > 0xFFFF { // Surrogate pair
+				r -= 0x10000
+				fmt.Fprintf(&out, `\u%04X\u%04X`, 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			} else {
+				fmt.Fprintf(&out, `\u%04X`, r)
+			}
+		} else {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
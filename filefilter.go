@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// File filters: size, modification time, and content type sniffed from the first bytes.
+// So a mixed share can be indexed without reading every video, or re-extracting text that's already text.
+
+var (
+	minFileSize    int64     = 0 // 0 for no limit
+	maxFileSize    int64     = 0
+	modifiedSince  time.Time // Zero for no limit
+	modifiedBefore time.Time
+	onlyTypes      []string // Content types to read.  Empty is all.
+	skipTypes      []string // Content types not to read.
+)
+
+type magicNumber struct {
+	offset      int
+	magic       []byte
+	contentType string
+}
+
+// Checked in order, so more specific entries go first.
+var magicNumbers = []magicNumber{
+	{0, []byte("\x7FELF"), "executable"},
+	{0, []byte("MZ"), "executable"},
+	{0, []byte{0xFE, 0xED, 0xFA, 0xCE}, "executable"}, // Mach-O
+	{0, []byte{0xFE, 0xED, 0xFA, 0xCF}, "executable"},
+	{0, []byte{0xCE, 0xFA, 0xED, 0xFE}, "executable"},
+	{0, []byte{0xCF, 0xFA, 0xED, 0xFE}, "executable"},
+	{0, []byte{0xCA, 0xFE, 0xBA, 0xBE}, "executable"}, // Mach-O universal, or Java class
+	{0, []byte("#!"), "executable"},
+	{0, []byte("%PDF"), "pdf"},
+	{0, []byte("PK\x03\x04"), "archive"}, // Also DOCX, XLSX, ODT, JAR...
+	{0, []byte("7z\xBC\xAF\x27\x1C"), "archive"},
+	{0, []byte("Rar!"), "archive"},
+	{0, []byte{0x1F, 0x8B}, "archive"},
+	{0, []byte("BZh"), "archive"},
+	{0, []byte("\xFD7zXZ\x00"), "archive"},
+	{0, []byte{0x28, 0xB5, 0x2F, 0xFD}, "archive"},
+	{257, []byte("ustar"), "archive"},
+	{0, []byte("\x89PNG"), "image"},
+	{0, []byte{0xFF, 0xD8, 0xFF}, "image"},
+	{0, []byte("GIF8"), "image"},
+	{0, []byte("BM"), "image"},
+	{0, []byte("II*\x00"), "image"},
+	{0, []byte("MM\x00*"), "image"},
+	{8, []byte("WEBP"), "image"},
+	{0, []byte{0x00, 0x00, 0x01, 0x00}, "image"}, // ICO
+	{8, []byte("WAVE"), "audio"},
+	{0, []byte("ID3"), "audio"},
+	{0, []byte("fLaC"), "audio"},
+	{0, []byte("OggS"), "audio"},
+	{0, []byte("MThd"), "audio"},
+	{8, []byte("AVI "), "video"},
+	{4, []byte("ftyp"), "video"},                 // MP4, MOV, M4A...
+	{0, []byte{0x1A, 0x45, 0xDF, 0xA3}, "video"}, // Matroska, WebM
+	{0, []byte("FLV"), "video"},
+}
+
+// Two-byte magics that text can start with too, e.g. a file starting "MZ..." or "BMW", need a second look.
+var magicChecks = map[string]func(header []byte) bool{
+	"MZ": isPE,
+	"BM": isBMP,
+}
+
+// A Windows executable's DOS header points (at 0x3C) to a PE header.  Plain DOS executables are binary, at least.
+func isPE(header []byte) bool {
+	if len(header) >= 0x40 {
+		peOffset := int(binary.LittleEndian.Uint32(header[0x3C:]))
+		if peOffset >= 0x40 && peOffset+4 <= len(header) && bytes.Equal(header[peOffset:peOffset+4], []byte("PE\x00\x00")) {
+			return true
+		}
+	}
+	return !isText(header)
+}
+
+// A bitmap's file header is followed by a DIB header, which starts with its size: one of a few known.
+func isBMP(header []byte) bool {
+	if len(header) >= 18 && slices.Contains([]uint32{12, 16, 40, 52, 56, 64, 108, 124}, binary.LittleEndian.Uint32(header[14:])) {
+		return true
+	}
+	return !isText(header)
+}
+
+var contentTypes = []string{"text", "executable", "pdf", "archive", "image", "audio", "video", "binary"}
+
+// / <summary>Guesses a file's content type from its first bytes.</summary>
+// / <returns>One of contentTypes.</returns>
+func SniffContentType(header []byte) string {
+	for _, m := range magicNumbers {
+		if len(header) >= m.offset+len(m.magic) && bytes.Equal(header[m.offset:m.offset+len(m.magic)], m.magic) {
+			if check := magicChecks[string(m.magic)]; check != nil && !check(header) {
+				continue
+			}
+			return m.contentType
+		}
+	}
+	if isText(header) {
+		return "text"
+	}
+	return "binary"
+}
+
+// Text has no NULs and is nearly all valid UTF-8, printable or whitespace.
+func isText(header []byte) bool {
+	if bytes.IndexByte(header, 0) >= 0 {
+		return false
+	}
+	bad := 0
+	for len(header) > 0 {
+		r, size := utf8.DecodeRune(header)
+		if (r == utf8.RuneError && size == 1 && len(header) >= utf8.UTFMax) || (r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f') {
+			bad++
+		}
+		header = header[size:]
+	}
+	return bad == 0
+}
+
+// / <summary>Parses a size with an optional K, M, G or T suffix (binary multiples), e.g. 512K.</summary>
+func ParseSize(size string) (int64, error) {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if len(size) == 0 {
+		return 0, nil
+	}
+	number := strings.TrimSuffix(size, "B")
+	multiplier := int64(1)
+	if len(number) > 0 {
+		if unit := strings.IndexByte("KMGT", number[len(number)-1]); unit >= 0 {
+			number = number[:len(number)-1]
+			multiplier = int64(1) << (10 * (unit + 1))
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad size %q (use e.g. 100, 512K or 2G)", size)
+	}
+	return n * multiplier, nil
+}
+
+// / <summary>Parses a time as a date (2006-01-02), date and time (2006-01-02T15:04:05 or with a space),
+// / RFC 3339, or an age before now: a number of days (7d), hours (12h), or a Go duration (90m).</summary>
+func ParseTime(when string) (time.Time, error) {
+	when = strings.TrimSpace(when)
+	if len(when) == 0 {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, when, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if days, found := strings.CutSuffix(when, "d"); found {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if age, err := time.ParseDuration(when); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("bad time %q (use e.g. 2024-01-31, \"2024-01-31 12:00\" or 7d)", when)
+}
+
+// / <summary>Parses a comma-delimited list of content types.</summary>
+func ParseContentTypes(list string) ([]string, error) {
+	var types []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+		if !slices.Contains(contentTypes, name) {
+			return nil, fmt.Errorf("unknown content type %q (known: %s)", name, strings.Join(contentTypes, ", "))
+		}
+		types = append(types, name)
+	}
+	return types, nil
+}
+
+// Reads enough of a file to sniff its type.
+func readHeader(fullFileName string) ([]byte, error) {
+	pFile, err := os.Open(fullFileName)
+	if err != nil {
+		return nil, err
+	}
+	defer pFile.Close()
+	header := make([]byte, 1024) // Enough for most PE header offsets.
+	n, _ := pFile.Read(header)
+	return header[:n], nil
+}
+
+// Is name a regular file, not a pipe or device?  Those can only be read once, so they aren't sniffed.
+func isRegularFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// / <summary>Does a file pass the size, time and content type filters?  Content types are only checked for regular files.</summary>
+func PassesFileFilters(folder string, file string) bool {
+	fullFileName := filepath.Join(folder, file)
+	if minFileSize > 0 || maxFileSize > 0 || !modifiedSince.IsZero() || !modifiedBefore.IsZero() {
+		info, err := os.Stat(fullFileName)
+		if err != nil {
+			ReportError("ERROR: %s", err.Error())
+			return false
+		}
+		if info.Size() < minFileSize || (maxFileSize > 0 && info.Size() > maxFileSize) {
+			return false
+		}
+		if (!modifiedSince.IsZero() && info.ModTime().Before(modifiedSince)) || (!modifiedBefore.IsZero() && !info.ModTime().Before(modifiedBefore)) {
+			return false
+		}
+	}
+	if (len(onlyTypes) > 0 || len(skipTypes) > 0) && isRegularFile(fullFileName) { // Pipes can only be read once.
+		header, err := readHeader(fullFileName)
+		if err != nil {
+			ReportError("ERROR: %s", err.Error())
+			return false
+		}
+		contentType := SniffContentType(header)
+		if debugOutput {
+			Debugf("%s: %s\n", fullFileName, contentType)
+		}
+		if (len(onlyTypes) > 0 && !slices.Contains(onlyTypes, contentType)) || slices.Contains(skipTypes, contentType) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// No inodes here: loops are found with os.SameFile, and -one-file-system does nothing.
+func fileIdentity(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GNU strings compatibility.
+// When run as "strings" (e.g. through a symlink), or with -strings as the first argument, the command line
+// is binutils strings': -n, -a, -t {o,d,x}, -e {s,S,b,l,B,L}, -f, -w and -o, with its output format.
+// These map onto the usual min-len, charset, UTF-16 and offset settings.
+
+var gnuStringsMode = false
+
+var stringsOffsetFormats = map[string]string{"o": "%7o", "d": "%7d", "x": "%7x"}
+
+// Is this run meant to be GNU strings?
+func IsStringsInvocation(args []string) bool {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(args[0])), ".exe")
+	return name == "strings" || (len(args) > 1 && (args[1] == "-strings" || args[1] == "--strings"))
+}
+
+// getopt-style arguments to what flag understands: -8 is -n 8, -tx is -t x, -af is -a -f.
+func expandStringsArgs(args []string) []string {
+	var expanded []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...)
+		}
+		if len(arg) < 2 || arg[0] != '-' || arg[1] == '-' {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if _, err := strconv.Atoi(arg[1:]); err == nil {
+			expanded = append(expanded, "-n", arg[1:])
+			continue
+		}
+		for j := 1; j < len(arg); j++ {
+			if strings.IndexByte("nte", arg[j]) >= 0 && j+1 < len(arg) { // Option with its value attached
+				expanded = append(expanded, "-"+arg[j:j+1], arg[j+1:])
+				break
+			}
+			expanded = append(expanded, "-"+arg[j:j+1])
+		}
+	}
+	return expanded
+}
+
+// / <summary>Runs as GNU strings.</summary>
+// / <param name="args">Arguments, without the program name or -strings.</param>
+// / <returns>Exit status: 0, or 1 if a file couldn't be read, as strings does.</returns>
+func StringsMain(args []string) int {
+	flags := flag.NewFlagSet("strings", flag.ContinueOnError)
+	minLen := flags.Int("n", 4, "Print sequences of at least this many characters.")
+	flags.IntVar(minLen, "bytes", 4, "Same as -n.")
+	flags.Bool("a", true, "Scan the whole file.  (Always.)")
+	flags.Bool("all", true, "Same as -a.")
+	radix := flags.String("t", "", "Print the offset of each string, in o (octal), d (decimal) or x (hex).")
+	flags.StringVar(radix, "radix", "", "Same as -t.")
+	octal := flags.Bool("o", false, "Same as -t o.")
+	encoding := flags.String("e", "s", "Character encoding: s (7-bit), S (8-bit), b/l (16-bit big/little-endian), B/L (32-bit).")
+	flags.StringVar(encoding, "encoding", "s", "Same as -e.")
+	flags.BoolVar(&withFileName, "f", false, "Print the file name before each string.")
+	flags.BoolVar(&withFileName, "print-file-name", false, "Same as -f.")
+	allWhitespace := flags.Bool("w", false, "Include all whitespace, such as newlines, in strings.")
+	flags.BoolVar(allWhitespace, "include-all-whitespace", false, "Same as -w.")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: strings [option(s)] [file(s)]\n Display printable strings in [file(s)] (stdin by default)\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(expandStringsArgs(args)); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	gnuStringsMode = true
+	noExpansion = true // strings doesn't look inside archives.
+	latin1HighBytes = false
+	if *octal {
+		*radix = "o"
+	}
+	if len(*radix) > 0 {
+		if _, found := stringsOffsetFormats[*radix]; !found {
+			fmt.Fprintf(os.Stderr, "strings: invalid radix: %s\n", *radix)
+			return 1
+		}
+		writeOffset = true
+	}
+	charsetSpec := "print,tab"
+	if *allWhitespace {
+		charsetSpec += ",whitespace"
+	}
+	utf16 := false
+	switch *encoding {
+	case "s":
+	case "S":
+		charsetSpec += ",high"
+	case "b", "l", "B", "L":
+		utf16 = true
+		wideOnly = true
+		wideTerminated = false
+		wideBigEndian = *encoding == "b" || *encoding == "B"
+		wideCharBytes = IIFInt(*encoding == "B" || *encoding == "L", 4, 2)
+	default:
+		fmt.Fprintf(os.Stderr, "strings: invalid encoding: %s\n", *encoding)
+		return 1
+	}
+	asciiCharset, _ = ParseCharset(charsetSpec)
+	stringsOffsetFormat = stringsOffsetFormats[*radix]
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, input := range inputs {
+		if input == "-" {
+			contents, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				ReportError("strings: {standard input}: %s", err.Error())
+				continue
+			}
+			asciifyBlob("", "{standard input}", "", contents, *minLen, false, 0, "", utf16)
+		} else {
+			AsciifyFile(filepath.Dir(input), filepath.Base(input), *minLen, false, 0, "", utf16)
+		}
+	}
+	return IIFInt(len(errorList) > 0, 1, 0)
+}
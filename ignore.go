@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// .asciiignore files.
+// Directory scans read .asciiignore (and .gitignore with -gitignore) in each directory they enter, with gitignore
+// semantics: # comments, ! to re-include, a trailing / for directories only, a / elsewhere anchors the pattern to the
+// ignore file's directory, ** for any directories.  Rules apply to that directory and below, and later rules win.
+// Only files found by the scan are ignored, not those named on the command line or with -files-from.
+
+type ignoreRule struct {
+	base     string // Directory of the ignore file, relative to the start, / separated.  Empty for the start.
+	pattern  []string
+	negate   bool
+	dirOnly  bool
+	anchored bool // Matched against the path from base, not just the name.
+}
+
+var (
+	ignoreFileNames = []string{".asciiignore"}
+	useIgnoreFiles  = true
+	ignoreRules     []ignoreRule // Rules in effect for the current directory, outermost first.
+)
+
+// Parses one line of an ignore file.  False for blank lines and comments.
+func parseIgnoreRule(base string, line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimLeft(line, "/")
+	}
+	if len(line) == 0 {
+		return ignoreRule{}, false
+	}
+	rule.pattern = strings.Split(line, "/")
+	return rule, true
+}
+
+// / <summary>Adds the rules from any ignore files in folder to ignoreRules.</summary>
+// / <returns>How many rules there were before, to restore when leaving the directory.</returns>
+func LoadIgnoreFiles(folder string) int {
+	previous := len(ignoreRules)
+	if !useIgnoreFiles {
+		return previous
+	}
+	base := relativeScanPath(folder, "")
+	if base == "." {
+		base = ""
+	}
+	for _, name := range ignoreFileNames {
+		pFile, err := os.Open(filepath.Join(folder, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(pFile)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(base, scanner.Text()); ok {
+				ignoreRules = append(ignoreRules, rule)
+			}
+		}
+		pFile.Close()
+	}
+	return previous
+}
+
+func (rule ignoreRule) Matches(relativePath string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	if len(rule.base) > 0 {
+		if !strings.HasPrefix(relativePath, rule.base+"/") {
+			return false
+		}
+		relativePath = relativePath[len(rule.base)+1:]
+	}
+	if rule.anchored {
+		return matchSegments(rule.pattern, strings.Split(relativePath, "/"))
+	}
+	matched, _ := path.Match(rule.pattern[0], path.Base(relativePath))
+	return matched
+}
+
+// Is a file or directory ignored by the rules in effect?  The last matching rule decides.
+// The ignore files themselves are always skipped.  Files named as inputs never are.
+func IsIgnored(folder string, name string, isDir bool) bool {
+	if !isDir && IsExplicitPath(folder, name) {
+		return false
+	}
+	if useIgnoreFiles && !isDir && slices.Contains(ignoreFileNames, name) {
+		return true
+	}
+	relative := relativeScanPath(folder, name)
+	ignored := false
+	for _, rule := range ignoreRules {
+		if rule.Matches(relative, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Indicator classification.
+// Tags found strings with what they contain - URLs, addresses, paths, hashes and the like - so
+// they can be reported or filtered without piping the output through a pile of grep scripts.
+
+type indicator struct {
+	name     string
+	pattern  *regexp.Regexp
+	validate func(match string) bool                 // Optional check of each match, for what a regexp can't do.
+	context  func(s string, start int, end int) bool // Optional check of the text around a match.
+}
+
+// Matches of this indicator in s, as [start, end] pairs.
+// If the pattern has a capture group, the match is the first group rather than the whole pattern.
+func (ind indicator) FindAll(s string) [][]int {
+	var valid [][]int
+	for _, m := range ind.pattern.FindAllStringSubmatchIndex(s, -1) {
+		if len(m) >= 4 && m[2] >= 0 {
+			m = m[2:4]
+		} else {
+			m = m[0:2]
+		}
+		if (ind.validate == nil || ind.validate(s[m[0]:m[1]])) && (ind.context == nil || ind.context(s, m[0], m[1])) {
+			valid = append(valid, m)
+		}
+	}
+	return valid
+}
+
+func (ind indicator) Matches(s string) bool {
+	return len(ind.FindAll(s)) > 0
+}
+
+// A valid address with a digit in it, so hex-letter names like ee::ff or "::" in code don't count.
+func isIPv6(match string) bool {
+	return strings.Contains(match, ":") && strings.ContainsAny(match, "0123456789") && net.ParseIP(match) != nil
+}
+
+func isIdentifierChar(b byte) bool {
+	return b == '_' || isDigit(b) || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// Not part of a longer name or path, like std::vector, Error::NotFound or a::b::c.
+func ipv6Context(s string, start int, end int) bool {
+	if start > 0 && (isIdentifierChar(s[start-1]) || s[start-1] == ':' || s[start-1] == '.') {
+		return false
+	}
+	if end < len(s) && (isIdentifierChar(s[end]) || s[end] == ':') {
+		return false
+	}
+	return !(end+1 < len(s) && s[end] == '.' && isDigit(s[end+1]))
+}
+
+// Up to eight colon-terminated hex groups, any of them empty for ::, then a last group or an IPv4 address.  The
+// IPv4 form goes first, or the address's first number would end the match.  isIPv6 decides what's valid.
+var ipv6Pattern = regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,8}(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)(?:\.(?:25[0-5]|2[0-4]\d|1?\d?\d)){3}|[0-9a-f]{1,4})?`)
+
+var emailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+
+var indicators = []indicator{
+	{"url", regexp.MustCompile(`(?i)\b(?:https?|ftps?|file|wss?)://[^\s"'<>]+`), nil, nil},
+	{"email", emailPattern, nil, nil},
+	{"ipv4", regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`), nil, nil},
+	{"ipv6", ipv6Pattern, isIPv6, ipv6Context},
+	{"domain", regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+(?:com|net|org|edu|gov|mil|int|info|biz|io|co|us|uk|ca|au|de|fr|nl|eu|ch|se|no|es|it|pl|ru|cn|jp|kr|in|br|me|tv|app|dev|cloud|online|site|xyz|top|local|onion)\b`), nil, nil},
+	{"winpath", regexp.MustCompile(`(?i)(?:\b[a-z]:\\|\\\\[a-z0-9._$-]+\\)(?:[^\\/:*?"<>|\r\n]+\\)*[^\\/:*?"<>|\r\n]*`), nil, nil},
+	{"unixpath", regexp.MustCompile(`(?:^|[\s"'=(])(?:~|/[\w.+-]+)(?:/[\w.+-]+)+/?`), nil, nil},
+	{"regkey", regexp.MustCompile(`(?i)\b(?:HKEY_[A-Z_]+|HKLM|HKCU|HKCR|HKU|HKCC)\\|\b(?:SOFTWARE|SYSTEM)\\(?:Microsoft|CurrentControlSet|Classes|Policies|Wow6432Node)\\`), nil, nil},
+	{"guid", regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), nil, nil},
+	{"mac", regexp.MustCompile(`(?i)\b(?:[0-9a-f]{2}:){5}[0-9a-f]{2}\b|\b(?:[0-9a-f]{2}-){5}[0-9a-f]{2}\b`), nil, nil},
+	{"md5", regexp.MustCompile(`\b[0-9a-fA-F]{32}\b`), nil, nil},
+	{"sha1", regexp.MustCompile(`\b[0-9a-fA-F]{40}\b`), nil, nil},
+	{"sha256", regexp.MustCompile(`\b[0-9a-fA-F]{64}\b`), nil, nil},
+	{"sha512", regexp.MustCompile(`\b[0-9a-fA-F]{128}\b`), nil, nil},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), nil, nil},
+}
+
+// Shorthands for groups of tags.
+var indicatorGroups = map[string][]string{
+	"ip":   {"ipv4", "ipv6"},
+	"path": {"winpath", "unixpath"},
+	"hash": {"md5", "sha1", "sha256", "sha512"},
+}
+
+var (
+	showTags   = false  // Preface strings with their tags.
+	onlyTags   []string // Only output strings with one of these tags.  Empty is no filter.
+	onlyAnyTag = false  // Only output strings with any tag.
+)
+
+func IndicatorNames() []string {
+	var names []string
+	for _, ind := range indicators {
+		names = append(names, ind.name)
+	}
+	return names
+}
+
+// / <summary>Sets the tag filter from a comma-delimited list of tag and group names, or "any".</summary>
+func SetTagFilter(list string) error {
+	known := IndicatorNames()
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+		if name == "any" {
+			onlyAnyTag = true
+		} else if group, found := indicatorGroups[name]; found {
+			onlyTags = append(onlyTags, group...)
+		} else if slices.Contains(known, name) {
+			onlyTags = append(onlyTags, name)
+		} else {
+			return fmt.Errorf("unknown tag %q (known: any, ip, path, hash, %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// Names of the indicators found in s.
+func ClassifyString(s string) []string {
+	var tags []string
+	for _, ind := range indicators {
+		if ind.Matches(s) {
+			tags = append(tags, ind.name)
+		}
+	}
+	return tags
+}
+
+// Does a string with these tags pass -only-tags?
+func PassesTagFilter(tags []string) bool {
+	if onlyAnyTag && len(tags) > 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if slices.Contains(onlyTags, tag) {
+			return true
+		}
+	}
+	return !onlyAnyTag && len(onlyTags) == 0
+}
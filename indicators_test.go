@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIPv6Detection(t *testing.T) {
+	tests := []struct {
+		text string
+		ipv6 bool
+	}{
+		{"listen on ::1 port 80", true},
+		{"link fe80::1 up", true},
+		{"mapped ::ffff:192.168.1.1 here", true},
+		{"net 2001:db8::/32", true},
+		{"addr 2001:0db8:85a3:0000:0000:8a2e:0370:7334.", true},
+		{"[fe80::1%eth0]:22", true},
+		// C++ and Rust paths
+		{"std::vector<int>", false},
+		{"return Error::NotFound;", false},
+		{"ee::ff", false},
+		{"a::b::c", false},
+		{"use std::io::Read;", false},
+		// Times and MAC addresses
+		{"at 12:30:45", false},
+		{"mac 00:1a:2b:3c:4d:5e", false},
+	}
+	for _, test := range tests {
+		if found := slices.Contains(ClassifyString(test.text), "ipv6"); found != test.ipv6 {
+			t.Errorf("ipv6 in %q: got %v, want %v", test.text, found, test.ipv6)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Command-line inputs.
+// Any number of files, directories and masks, with - for standard input, and flags before, between or after them.
+// -files-from adds a list of paths, e.g. from find -print0 or a database, one per line or NUL-delimited with -null.
+
+const stdinName = "(standard input)" // As grep names it.
+
+var stdinContents []byte // Read once, in case there are two passes.
+
+var (
+	filesFrom     = ""                    // File listing paths to scan.  - is standard input.
+	filesFromNull = false                 // The list is NUL-delimited rather than one per line.
+	explicitPaths = make(map[string]bool) // Absolute paths of files named on the command line or in the list.
+)
+
+// Was this file named as an input, rather than found in a directory?  Rules for what to skip in directories,
+// such as .asciiignore, don't apply to it.
+func IsExplicitPath(folder string, name string) bool {
+	return explicitPaths[absolutePath(filepath.Join(folder, name))]
+}
+
+// / <summary>Parses the command line, allowing flags after positional arguments.  Everything after -- is an input.</summary>
+// / <returns>The positional arguments, in order.</returns>
+func ParseCommandLine(args []string) []string {
+	var inputs []string
+	for {
+		flag.CommandLine.Parse(args)
+		consumed := len(args) - flag.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(inputs, flag.Args()...)
+		}
+		if flag.NArg() == 0 {
+			return inputs
+		}
+		inputs = append(inputs, flag.Arg(0))
+		args = flag.Args()[1:]
+	}
+}
+
+// / <summary>Reads the -files-from list.</summary>
+// / <returns>The paths, without blank entries.</returns>
+func ReadFilesFrom(listFile string, nullDelimited bool) ([]string, error) {
+	var contents []byte
+	var err error
+	if listFile == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(listFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(string(contents), IIF(nullDelimited, "\x00", "\n")) {
+		if !nullDelimited {
+			path = strings.TrimSuffix(path, "\r")
+		}
+		if len(path) > 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// / <summary>Scans a path from -files-from.  It's taken literally, not as a mask.</summary>
+func ScanListedPath(path string, recurse bool, minLen int, utf8 bool, utf16 bool, alphaRatio int, skipOlderMatch string) (dirCount int, fileCount int) {
+	info, err := os.Stat(path)
+	if err != nil {
+		ReportError("ERROR: %s", err.Error())
+		return 0, 0
+	}
+	if info.IsDir() {
+		return ScanInput(path, recurse, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
+	}
+	explicitPaths[absolutePath(path)] = true
+	startPath = filepath.Dir(path)
+	if !IsReadable(filepath.Dir(path), filepath.Base(path)) {
+		return 0, 0
+	}
+	ProcessFile(filepath.Dir(path), filepath.Base(path), minLen, utf8, utf16, alphaRatio, skipOlderMatch)
+	return 0, 1
+}
+
+// / <summary>Splits an input into the folder to scan and the file mask within it.
+// / A directory is all of its files, a file or mask is relative to the current directory unless it has a path.</summary>
+func InputFolderAndMask(input string) (folder string, mask string) {
+	if info, err := os.Stat(input); err == nil && info.IsDir() {
+		return input, "*"
+	}
+	if strings.Contains(input, string(os.PathSeparator)) {
+		return filepath.Dir(input), filepath.Base(input)
+	}
+	folder, _ = os.Getwd()
+	return folder, input
+}
+
+// / <summary>Scans one command-line input: standard input, a file, a directory or a mask.</summary>
+// / <returns>Directories and files processed.</returns>
+func ScanInput(input string, recurse bool, minLen int, utf8 bool, utf16 bool, alphaRatio int, skipOlderMatch string) (dirCount int, fileCount int) {
+	if input == "-" {
+		if collectingVersions {
+			return 0, 0
+		}
+		if stdinContents == nil {
+			contents, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				ReportError("ERROR: %s: %s", stdinName, err.Error())
+				return 0, 0
+			}
+			stdinContents = contents
+		}
+		includedFileNames = append(includedFileNames, stdinName)
+		asciifyBlob("", stdinName, "", stdinContents, minLen, utf8, alphaRatio, skipOlderMatch, utf16)
+		return 0, 1
+	}
+
+	folder, fileName := InputFolderAndMask(input)
+	if debugOutput {
+		Debugf("Directory: %s\n", folder)
+	}
+	if _, err := os.Stat(folder); err != nil {
+		ReportError("ASCII Error: Directory not found! %s", err.Error())
+		return 0, 0
+	}
+	// Determine if we have a file mask
+	if info, err := os.Stat(filepath.Join(folder, fileName)); err == nil {
+		if !info.IsDir() {
+			explicitPaths[absolutePath(filepath.Join(folder, fileName))] = true
+		}
+	} else {
+		// Test as a glob matcher.
+		files, err := filepath.Glob(filepath.Join(folder, fileName))
+		if err != nil {
+			ReportError("Bad filename: Could not find nor use as a pattern %s in %s.", fileName, input)
+			return 0, 0
+		}
+		if len(files) == 0 && !recurse {
+			ReportError("No files matching %s in %s found.", fileName, folder)
+			return 0, 0
+		}
+	}
+	startPath = folder
+	StartTraversal(folder)
+	return RecurseDirectories(folder, recurse, fileName, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
+}
+
+// / <summary>Scans every command-line input in order, then the -files-from paths.</summary>
+func ScanInputs(inputs []string, listedPaths []string, recurse bool, minLen int, utf8 bool, utf16 bool, alphaRatio int, skipOlderMatch string) (dirCount int, fileCount int) {
+	for _, input := range inputs {
+		dirs, files := ScanInput(input, recurse, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
+		dirCount += dirs
+		fileCount += files
+	}
+	for _, path := range listedPaths {
+		if collectingVersions { // Versions are only selected in directories.
+			break
+		}
+		dirs, files := ScanListedPath(path, recurse, minLen, utf8, utf16, alphaRatio, skipOlderMatch)
+		dirCount += dirs
+		fileCount += files
+	}
+	return dirCount, fileCount
+}
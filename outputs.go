@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Recognizing our own output.
+// -o writes <file>.txt next to each file, and -p writes into one directory.  Scanning those again gives foo.txt.txt,
+// so each directory written to gets a .asciioutputs manifest listing the files written there, and scans skip them,
+// as well as any file written earlier in this run.  Nothing is guessed from names, so a notes.txt beside a notes
+// is read unless it's listed.  -scan-outputs reads listed files too.  Files named as inputs are always read.
+
+const outputManifestName = ".asciioutputs"
+
+var (
+	scanOutputs     = false
+	writtenOutputs  = make(map[string]bool)            // Absolute paths of files written this run.
+	outputManifests = make(map[string]map[string]bool) // Names listed in each directory's manifest, by absolute path.
+)
+
+func absolutePath(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(name)
+}
+
+// The names listed in a directory's manifest, read the first time it's needed.
+func outputManifest(folder string) map[string]bool {
+	folder = absolutePath(folder)
+	if listed, found := outputManifests[folder]; found {
+		return listed
+	}
+	listed := make(map[string]bool)
+	if pFile, err := os.Open(filepath.Join(folder, outputManifestName)); err == nil {
+		scanner := bufio.NewScanner(pFile)
+		for scanner.Scan() {
+			if len(scanner.Text()) > 0 {
+				listed[scanner.Text()] = true
+			}
+		}
+		pFile.Close()
+	}
+	outputManifests[folder] = listed
+	return listed
+}
+
+// Is this a manifest found by a directory scan?  They're never read, even with -scan-outputs.
+func IsOutputManifest(folder string, file string) bool {
+	return file == outputManifestName && !IsExplicitPath(folder, file)
+}
+
+// / <summary>Writes an output file, and lists it in its directory's manifest so it's never scanned.</summary>
+func WriteOutput(name string, contents string) error {
+	writtenOutputs[absolutePath(name)] = true
+	if err := os.WriteFile(name, []byte(contents), 0644); err != nil {
+		return err
+	}
+	listed := outputManifest(filepath.Dir(name))
+	if listed[filepath.Base(name)] {
+		return nil
+	}
+	pManifest, err := os.OpenFile(filepath.Join(filepath.Dir(name), outputManifestName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer pManifest.Close()
+	if _, err := fmt.Fprintln(pManifest, filepath.Base(name)); err != nil {
+		return err
+	}
+	listed[filepath.Base(name)] = true
+	return nil
+}
+
+// / <summary>Is this file one we wrote, this run or an earlier one?  Files named as inputs only if written this run.
+// / (The manifests themselves are IsOutputManifest.)</summary>
+func IsOwnOutput(folder string, file string) bool {
+	if writtenOutputs[absolutePath(filepath.Join(folder, file))] {
+		return true
+	}
+	return !scanOutputs && !IsExplicitPath(folder, file) && outputManifest(folder)[file]
+}
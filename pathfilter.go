@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Include/exclude path filtering for directory scans.
+// Patterns are matched against the path relative to the input's starting directory, with / separators.
+// ** matches any number of directories, e.g. "src/**/*.dll".  A pattern without a / matches the name at any depth,
+// e.g. "*.log".  Excluded directories are pruned: nothing under them is read.  Files named on the command line or
+// with -files-from are always read, as with .asciiignore.
+
+// A repeatable string flag.
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+var (
+	includePatterns    stringList // Only scan files matching one of these.  Empty is all files.
+	excludePatterns    stringList // Don't scan files matching any of these.
+	excludeDirPatterns stringList // Don't enter directories matching any of these.
+)
+
+// / <summary>Matches a / separated path against a pattern where ** is any number of directories.</summary>
+func MatchPathPattern(pattern string, relativePath string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(relativePath))
+		return matched
+	}
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(relativePath, "/"))
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(segments); skip++ {
+				if matchSegments(pattern[1:], segments[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+	return len(segments) == 0
+}
+
+func matchesAny(patterns []string, relativePath string) bool {
+	for _, pattern := range patterns {
+		if MatchPathPattern(pattern, relativePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Path relative to the input's starting directory, for matching.
+func relativeScanPath(folder string, name string) string {
+	relative, err := filepath.Rel(startPath, filepath.Join(folder, name))
+	if err != nil {
+		relative = name
+	}
+	return filepath.ToSlash(relative)
+}
+
+// Does a file pass -include and -exclude?  Files named as inputs always do.
+func PassesPathFilter(folder string, file string) bool {
+	if IsExplicitPath(folder, file) {
+		return true
+	}
+	relative := relativeScanPath(folder, file)
+	if len(includePatterns) > 0 && !matchesAny(includePatterns, relative) {
+		return false
+	}
+	return !matchesAny(excludePatterns, relative)
+}
+
+// Should the scan skip this directory, and everything under it?
+func PruneDirectory(folder string, dir string) bool {
+	return matchesAny(excludeDirPatterns, relativeScanPath(folder, dir))
+}
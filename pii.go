@@ -0,0 +1,120 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Personal data detection, for keeping it out of the text indexes built with -o/-p.
+// Uses the same report/redact machinery as the secret detectors.
+
+// Digits only, from a match that may have spaces, dashes, dots and parentheses.
+func digitsOf(match string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, match)
+}
+
+// Luhn checksum, used by payment cards and some national IDs.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return len(digits) > 0 && sum%10 == 0
+}
+
+func isCardNumber(match string) bool {
+	digits := digitsOf(match)
+	return len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits)
+}
+
+var phoneCountryCode = regexp.MustCompile(`^\+\d{1,3}[ .-]?`)
+
+// Phone numbers use one separator throughout, e.g. 555-123-4567 or 555.123.4567, not counting the country code
+// or after an (area code).  Space-separated groups without either are as likely a table of numbers: 1024 2048 4096.
+func isPhoneNumber(match string) bool {
+	digits := digitsOf(match)
+	if len(digits) < 9 || len(digits) > 15 {
+		return false
+	}
+	hasCountry := phoneCountryCode.MatchString(match)
+	rest := phoneCountryCode.ReplaceAllString(match, "")
+	hasArea := strings.HasPrefix(rest, "(")
+	if hasArea {
+		rest = rest[strings.Index(rest, ")")+1:]
+	}
+	separators := strings.FieldsFunc(strings.TrimLeft(rest, " .-"), func(r rune) bool { return r >= '0' && r <= '9' })
+	for _, separator := range separators {
+		if separator != separators[0] {
+			return false
+		}
+	}
+	return hasCountry || hasArea || len(separators) == 0 || separators[0] != " "
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// Not part of a dotted number, such as an IP address (192.168.100.200) or version (1.234.5678.90).
+func phoneContext(s string, start int, end int) bool {
+	if start >= 2 && s[start-1] == '.' && isDigit(s[start-2]) {
+		return false
+	}
+	return !(end+1 < len(s) && s[end] == '.' && isDigit(s[end+1]))
+}
+
+// US Social Security numbers: no 000, 666 or 9xx area, 00 group or 0000 serial.
+func isSSN(match string) bool {
+	digits := digitsOf(match)
+	area, group, serial := digits[0:3], digits[3:5], digits[5:9]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func isSIN(match string) bool {
+	digits := digitsOf(match)
+	return digits[0] != '0' && digits[0] != '8' && luhnValid(digits)
+}
+
+// IBAN mod-97 check: move the first four characters to the end, letters to numbers, remainder must be 1.
+func isIBAN(match string) bool {
+	iban := strings.ReplaceAll(strings.ToUpper(match), " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	remainder := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+var piiDetectors = detectorSet{
+	name: "PII",
+	detectors: []indicator{
+		{"email", emailPattern, nil, nil},
+		{"card", regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), isCardNumber, nil},
+		{"iban", regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`), isIBAN, nil},
+		{"us-ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), isSSN, nil},
+		{"uk-nino", regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`), nil, nil},
+		{"ca-sin", regexp.MustCompile(`\b\d{3}[ -]\d{3}[ -]\d{3}\b`), isSIN, nil},
+		{"phone", regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?|\b\d{2,4}[ .-])\d{3,4}[ .-]\d{3,4}\b`), isPhoneNumber, phoneContext},
+	},
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestPhoneDetection(t *testing.T) {
+	tests := []struct {
+		text  string
+		phone bool
+	}{
+		{"call 555-123-4567 now", true},
+		{"call 555.123.4567 now", true},
+		{"call (555) 123-4567 now", true},
+		{"intl +44 20 7946 0958", true},
+		{"intl +1 555-123-4567", true},
+		// IP addresses
+		{"host 192.168.100.200 up", false},
+		{"gateway 10.100.200.254", false},
+		{"range 172.16.254.1-172.16.254.255", false},
+		// Version strings
+		{"version 10.0.19041.1288", false},
+		{"file version 6.1.7601.24214", false},
+		{"release 2024.1.15", false},
+		// Numeric tables
+		{"sizes 1024 2048 4096", false},
+		{"0010 0020 0030 0040 0050", false},
+		{"1000 2000 3000 4000", false},
+		// Mixed separators
+		{"555-123.4567", false},
+		{"555 123-4567", false},
+	}
+	for _, test := range tests {
+		found := false
+		for _, detector := range piiDetectors.detectors {
+			if detector.name == "phone" && detector.Matches(test.text) {
+				found = true
+			}
+		}
+		if found != test.phone {
+			t.Errorf("phone in %q: got %v, want %v", test.text, found, test.phone)
+		}
+	}
+}
+
+func TestPIIDetectsOthersAroundIPs(t *testing.T) {
+	found := piiDetectors.Detect("card 4111 1111 1111 1111 from 192.168.100.200")
+	if len(found) != 1 || found[0].name != "card" {
+		t.Errorf("expected only the card, got %v", found)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"embed"
+	"math"
+	"unicode"
+)
+
+// Natural-language plausibility scoring.
+// A character trigram model, trained at startup on the small corpus embedded below, estimates how
+// likely a string is to be prose rather than binary noise.  The alpha-ratio check only counts letters,
+// so "xQ7zK1pL" passes it; this scores the order the characters appear in.
+// Scores are 0-100: 0 is what uniformly random characters earn, 100 is what the training corpus earns.
+
+//go:embed corpus/*.txt
+var corpusFiles embed.FS
+
+// Character classes: a-z, any other letter, whitespace, digit, everything else.
+const (
+	classOtherLetter = 26
+	classSpace       = 27
+	classDigit       = 28
+	classOther       = 29
+	classCount       = 30
+)
+
+type plausibilityModel struct {
+	unigrams  [classCount]float64
+	bigrams   [classCount][classCount]float64
+	trigrams  [classCount][classCount][classCount]float64
+	total     float64
+	baseline  float64 // Average log probability of random text
+	reference float64 // Average log probability of the corpus
+}
+
+var (
+	minPlausibility = 0 // 0 disables the check
+	languageModel   *plausibilityModel
+)
+
+// Interpolation weights for trigram, bigram and unigram estimates.
+const (
+	lambda3 = 0.6
+	lambda2 = 0.3
+	lambda1 = 0.1
+)
+
+func characterClass(r rune) int {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return int(r - 'a')
+	case r >= 'A' && r <= 'Z':
+		return int(r - 'A')
+	case unicode.IsLetter(r):
+		return classOtherLetter
+	case unicode.IsSpace(r):
+		return classSpace
+	case unicode.IsDigit(r):
+		return classDigit
+	}
+	return classOther
+}
+
+// Converts text to class indices, collapsing whitespace runs and starting with a space so the first
+// character is scored as the start of a word.
+func classSequence(text string) []int {
+	seq := []int{classSpace}
+	for _, r := range text {
+		c := characterClass(r)
+		if c == classSpace && seq[len(seq)-1] == classSpace {
+			continue
+		}
+		seq = append(seq, c)
+	}
+	return seq
+}
+
+// Builds the model from every file in the embedded corpus.
+func LoadPlausibilityModel() *plausibilityModel {
+	model := &plausibilityModel{}
+	var sequences [][]int
+	entries, _ := corpusFiles.ReadDir("corpus")
+	for _, entry := range entries {
+		content, err := corpusFiles.ReadFile("corpus/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		seq := classSequence(string(content))
+		sequences = append(sequences, seq)
+		for i, c := range seq {
+			model.unigrams[c]++
+			model.total++
+			if i >= 1 {
+				model.bigrams[seq[i-1]][c]++
+			}
+			if i >= 2 {
+				model.trigrams[seq[i-2]][seq[i-1]][c]++
+			}
+		}
+	}
+
+	// Expected score of uniformly random classes.
+	sum := 0.0
+	for a := 0; a < classCount; a++ {
+		for b := 0; b < classCount; b++ {
+			for c := 0; c < classCount; c++ {
+				sum += model.logProbability(a, b, c)
+			}
+		}
+	}
+	model.baseline = sum / (classCount * classCount * classCount)
+
+	sum = 0.0
+	count := 0
+	for _, seq := range sequences {
+		for i := 2; i < len(seq); i++ {
+			sum += model.logProbability(seq[i-2], seq[i-1], seq[i])
+			count++
+		}
+	}
+	model.reference = sum / float64(max(count, 1))
+	return model
+}
+
+func (model *plausibilityModel) logProbability(a, b, c int) float64 {
+	p := lambda1 * (model.unigrams[c] + 1) / (model.total + classCount)
+	bigramContext := 0.0
+	for _, n := range model.bigrams[b] {
+		bigramContext += n
+	}
+	if bigramContext > 0 {
+		p += lambda2 * model.bigrams[b][c] / bigramContext
+	}
+	trigramContext := model.bigrams[a][b]
+	if trigramContext > 0 {
+		p += lambda3 * model.trigrams[a][b][c] / trigramContext
+	}
+	return math.Log(p)
+}
+
+// Returns the 0-100 plausibility of text as natural language.
+func (model *plausibilityModel) Score(text string) int {
+	seq := classSequence(text)
+	if len(seq) < 3 {
+		return 0
+	}
+	sum := 0.0
+	for i := 2; i < len(seq); i++ {
+		sum += model.logProbability(seq[i-2], seq[i-1], seq[i])
+	}
+	avg := sum / float64(len(seq)-2)
+	score := 100 * (avg - model.baseline) / (model.reference - model.baseline)
+	return int(math.Round(math.Max(0, math.Min(100, score))))
+}
+
+// Convenience for the string vetting: loads the model on first use.
+func PlausibilityScore(text string) int {
+	if languageModel == nil {
+		languageModel = LoadPlausibilityModel()
+	}
+	return languageModel.Score(text)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Secret and credential detection.
+// Detectors run over found strings and can report what they find, and/or redact it from the output,
+// including the .txt files written by -o/-p.  Useful for scanning backups and build artifacts.
+
+type detectorSet struct {
+	name      string // For reports, e.g. "SECRET"
+	detectors []indicator
+	report    bool
+	redact    bool
+	redacted  map[string]int // Per-file count of redactions by detector, for the summary.
+}
+
+// Shannon entropy of s in bits per character.  Random tokens are high; words and placeholders are low.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+var secretDetectors = detectorSet{
+	name: "SECRET",
+	detectors: []indicator{
+		{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA|A3T[A-Z0-9])[A-Z0-9]{16}\b`), nil, nil},
+		{"aws-secret-key", regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|key).{0,20}?[=:\s"']+([A-Za-z0-9/+]{40})\b`), nil, nil},
+		{"private-key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----(?:[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----)?`), nil, nil},
+		{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`), nil, nil},
+		{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), nil, nil},
+		{"slack-webhook", regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`), nil, nil},
+		{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), nil, nil},
+		{"stripe-key", regexp.MustCompile(`\b(?:sk|rk)_live_[0-9a-zA-Z]{24,}\b`), nil, nil},
+		{"password-assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|pass)\b\s*[:=]\s*["']?([^\s"',;]{4,})`), nil, nil},
+		{"secret-assignment", regexp.MustCompile(`(?i)\b(?:secret|api[_-]?key|apikey|access[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret|token)\b\s*[:=]\s*["']?([^\s"',;]{16,})`),
+			func(match string) bool { return shannonEntropy(match) >= 3.5 }, nil},
+	},
+}
+
+// / <summary>Sets report and/or redact from a comma-delimited mode list.</summary>
+func (set *detectorSet) Configure(modes string) error {
+	for _, mode := range strings.Split(modes, ",") {
+		switch strings.ToLower(strings.TrimSpace(mode)) {
+		case "":
+		case "report":
+			set.report = true
+		case "redact":
+			set.redact = true
+		default:
+			return fmt.Errorf("%s mode must be report and/or redact, not %q", strings.ToLower(set.name), mode)
+		}
+	}
+	return nil
+}
+
+func (set *detectorSet) Active() bool {
+	return set.report || set.redact
+}
+
+type detection struct {
+	name       string
+	start, end int
+	set        *detectorSet
+	counted    *bool // Shared by the pieces of a split run, once one has counted it.  Nil for one string's own.
+}
+
+// The detector sets, for redaction, which does them together.
+var detectorSets = []*detectorSet{&secretDetectors, &piiDetectors}
+
+// All detections in src, in order, with overlaps merged into the first.
+func (set *detectorSet) Detect(src string) []detection {
+	var found []detection
+	for _, detector := range set.detectors {
+		for _, m := range detector.FindAll(src) {
+			found = append(found, detection{detector.name, m[0], m[1], set, nil})
+		}
+	}
+	return mergeDetections(found)
+}
+
+// Sorts detections, merging overlaps into the first.
+func mergeDetections(found []detection) []detection {
+	slices.SortFunc(found, func(a, b detection) int { return a.start - b.start })
+	var merged []detection
+	for _, d := range found {
+		if len(merged) > 0 && d.start < merged[len(merged)-1].end {
+			last := &merged[len(merged)-1]
+			last.end = max(last.end, d.end)
+			if last.counted == nil {
+				last.counted = d.counted
+			}
+			continue
+		}
+		merged = append(merged, d)
+	}
+	return merged
+}
+
+// Prints each detection in a found string, for report mode, at its offset in the file.
+func (set *detectorSet) Report(fileName string, f foundString) {
+	for _, d := range set.Detect(f.text) {
+		fmt.Printf("%s %s: %s @ %08X: %s\n", set.name, d.name, fileName, f.fileOffset(d.start), f.text[d.start:d.end])
+	}
+}
+
+// All detections in src by the sets in redact mode.
+func RedactDetections(src string) []detection {
+	var found []detection
+	for _, set := range detectorSets {
+		if set.redact {
+			found = append(found, set.Detect(src)...)
+		}
+	}
+	return mergeDetections(found)
+}
+
+// / <summary>Masks detections in src, for redact mode, counting them for the summaries.
+// / A detection split between pieces of a run is only counted the first time.</summary>
+// / <param name="detections">Sorted, not overlapping, e.g. from RedactDetections.</param>
+func Redact(src string, detections []detection) string {
+	for i := len(detections) - 1; i >= 0; i-- {
+		d := detections[i]
+		src = src[:d.start] + "[REDACTED:" + d.name + "]" + src[d.end:]
+		if d.counted != nil && *d.counted {
+			continue
+		}
+		if d.set.redacted == nil {
+			d.set.redacted = make(map[string]int)
+		}
+		d.set.redacted[d.name]++
+		if d.counted != nil {
+			*d.counted = true
+		}
+	}
+	return src
+}
+
+// Prints what was redacted from a file, if anything, and starts over for the next.  On stderr, out of the way of the strings.
+func (set *detectorSet) Summarize(fileName string) {
+	if len(set.redacted) == 0 {
+		return
+	}
+	var counts []string
+	for _, detector := range set.detectors {
+		if count := set.redacted[detector.name]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count, detector.name))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s redacted in %s: %s\n", set.name, fileName, strings.Join(counts, ", "))
+	set.redacted = nil
+}
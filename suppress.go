@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Built-in suppression sets for common document and executable boilerplate, so the usual font names,
+// XML tags and runtime messages don't have to be maintained by hand in -suppress.
+// Each set is suppress/<name>.txt: one entry per line, case-insensitive, # comments, * wildcards.
+
+//go:embed suppress/*.txt
+var suppressionSetFiles embed.FS
+
+// Names of the built-in suppression sets.
+func SuppressionSetNames() []string {
+	var names []string
+	entries, _ := suppressionSetFiles.ReadDir("suppress")
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+	}
+	return names
+}
+
+// / <summary>Adds the named built-in sets to suppressList.</summary>
+// / <param name="names">Comma-delimited set names, or "all".</param>
+// / <returns>Error if a set doesn't exist.</returns>
+func LoadSuppressionSets(names string) error {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+		if name == "all" {
+			return LoadSuppressionSets(strings.Join(SuppressionSetNames(), ","))
+		}
+		pFile, err := suppressionSetFiles.Open("suppress/" + name + ".txt")
+		if err != nil {
+			return fmt.Errorf("no suppression set %q (available: %s)", name, strings.Join(SuppressionSetNames(), ", "))
+		}
+		scanner := bufio.NewScanner(pFile)
+		for scanner.Scan() {
+			entry := strings.TrimSpace(scanner.Text())
+			if len(entry) == 0 || strings.HasPrefix(entry, "#") {
+				continue
+			}
+			suppressList = append(suppressList, strings.ToUpper(entry))
+		}
+		pFile.Close()
+	}
+	return nil
+}
+
+// Matches a suppression entry against an (upper-cased) string.  * in the entry matches any run of characters.
+func SuppressionMatch(testString string, entry string) bool {
+	if !strings.Contains(entry, "*") {
+		return testString == entry
+	}
+	parts := strings.Split(entry, "*")
+	if !strings.HasPrefix(testString, parts[0]) {
+		return false
+	}
+	testString = testString[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(testString, part)
+		if index < 0 {
+			return false
+		}
+		testString = testString[index+len(part):]
+	}
+	return strings.HasSuffix(testString, last)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Symlinks, loops and special files in directory scans.
+// By default symlinked directories aren't entered, symlinked files are read if they point at regular files, and
+// FIFOs, sockets and devices are skipped, since reading them can block forever.  Those named as inputs, like
+// <(...), are read.  -follow enters symlinked directories, remembering each directory entered (by device and inode
+// where the OS has them) so loops end.  -one-file-system stays on the device each input starts on.
+
+type fileID struct {
+	device uint64
+	inode  uint64
+}
+
+var (
+	followSymlinks   = false
+	oneFileSystem    = false
+	readSpecialFiles = false
+	visitedIDs       map[fileID]bool
+	visitedDirs      []os.FileInfo // Where there are no inodes, for os.SameFile.
+	startDevice      uint64
+	startDeviceKnown = false
+)
+
+// / <summary>Starts a directory scan from folder: forgets the directories visited, and notes its device.</summary>
+func StartTraversal(folder string) {
+	visitedIDs = make(map[fileID]bool)
+	visitedDirs = nil
+	startDeviceKnown = false
+	if info, err := os.Stat(folder); err == nil {
+		if id, ok := fileIdentity(info); ok {
+			startDevice, startDeviceKnown = id.device, true
+		}
+	}
+}
+
+// / <summary>Checks a directory before scanning it, and marks it visited.</summary>
+// / <returns>False if it's been visited already (a symlink loop), or it's on another file system with -one-file-system.</returns>
+func EnterDirectory(folder string) bool {
+	info, err := os.Stat(folder)
+	if err != nil {
+		ReportError("ERROR: %s", err.Error())
+		return false
+	}
+	if id, ok := fileIdentity(info); ok {
+		if oneFileSystem && startDeviceKnown && id.device != startDevice {
+			if debugOutput {
+				Debugf("Skipping %s: another file system.\n", folder)
+			}
+			return false
+		}
+		if visitedIDs[id] {
+			if debugOutput {
+				Debugf("Skipping %s: already visited.  (Symlink loop?)\n", folder)
+			}
+			return false
+		}
+		visitedIDs[id] = true
+		return true
+	}
+	for _, visited := range visitedDirs {
+		if os.SameFile(info, visited) {
+			if debugOutput {
+				Debugf("Skipping %s: already visited.  (Symlink loop?)\n", folder)
+			}
+			return false
+		}
+	}
+	visitedDirs = append(visitedDirs, info)
+	return true
+}
+
+// / <summary>Sorts a directory entry into a directory to scan, a file to read, or neither.</summary>
+func classifyEntry(folder string, entry fs.DirEntry) (isDir bool, isFile bool) {
+	mode := entry.Type()
+	if mode&fs.ModeSymlink != 0 {
+		info, err := os.Stat(filepath.Join(folder, entry.Name()))
+		if err != nil { // Dangling
+			if debugOutput {
+				Debugf("Skipping %s: %s\n", filepath.Join(folder, entry.Name()), err.Error())
+			}
+			return false, false
+		}
+		if info.IsDir() {
+			return followSymlinks, false
+		}
+		mode = info.Mode().Type()
+	}
+	if mode.IsDir() {
+		return true, false
+	}
+	if mode.IsRegular() || readSpecialFiles || IsExplicitPath(folder, entry.Name()) {
+		return false, true
+	}
+	if debugOutput {
+		Debugf("Skipping special file %s\n", filepath.Join(folder, entry.Name()))
+	}
+	return false, false
+}
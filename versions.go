@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keeping only the newest versions of files.
+// Files are grouped by name less the version part: what follows -skip-older-match's delimiter, or what
+// -version-key's regexp matches (its first group, if it has one).  Within a group the version is read as a date
+// (20220212, 2022-02-12, 2022_02_12 12-30...) or a version number (2.0, v1.10.3-beta, 12), newest first, with
+// the modification time deciding anything else.  -keep-newest keeps more than one.  Groups are per directory
+// unless -version-across-dirs, which looks at every directory scanned before reading any.
+
+type versionedFile struct {
+	path    string
+	key     string // The name without the version.
+	version string
+	modTime time.Time
+}
+
+var (
+	versionPattern     *regexp.Regexp
+	keepNewest         = 1
+	versionAcrossDirs  = false
+	collectingVersions = false // -version-across-dirs first pass: gather candidates, read nothing.
+	versionCandidates  []versionedFile
+	keptVersions       map[string]bool // -version-across-dirs: paths of the files to read.
+)
+
+var (
+	dateVersionPattern     = regexp.MustCompile(`(\d{4})[-_.]?(\d{2})[-_.]?(\d{2})(?:[T_ -]?(\d{2})[-_.:]?(\d{2})(?:[-_.:]?(\d{2}))?)?`)
+	semanticVersionPattern = regexp.MustCompile(`(\d+(?:\.\d+)*)(?:-([0-9A-Za-z.-]+))?`)
+)
+
+// Is -skip-older-match or -version-key in use?
+func VersionSelectionActive(skipOlderMatch string) bool {
+	return len(skipOlderMatch) > 0 || versionPattern != nil
+}
+
+// / <summary>Splits a file name into its group key and version.</summary>
+// / <returns>False if the name has no version, so it's always read.</returns>
+func versionKey(file string, skipOlderMatch string) (key string, version string, ok bool) {
+	if versionPattern != nil {
+		m := versionPattern.FindStringSubmatchIndex(file)
+		if m == nil {
+			return "", "", false
+		}
+		if len(m) >= 4 && m[2] >= 0 {
+			m = m[2:4]
+		}
+		return file[:m[0]] + "\x00" + file[m[1]:], file[m[0]:m[1]], true
+	}
+	skipIndex := strings.Index(file, skipOlderMatch)
+	if skipIndex < 0 {
+		return "", "", false
+	}
+	version = file[skipIndex+len(skipOlderMatch):]
+	return file[:skipIndex], strings.TrimSuffix(version, filepath.Ext(version)), true
+}
+
+// Date and time in a version, if it has a valid one.
+func parseDateVersion(version string) (time.Time, bool) {
+	m := dateVersionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return time.Time{}, false
+	}
+	var parts [6]int
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(m[i+1]) // Missing time parts are 0.
+	}
+	t := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.UTC)
+	if t.Year() != parts[0] || int(t.Month()) != parts[1] || t.Day() != parts[2] || t.Hour() != parts[3] || t.Minute() != parts[4] {
+		return time.Time{}, false // e.g. month 13: not a date.
+	}
+	return t, true
+}
+
+// Numeric parts and pre-release tag of a version, if it has numbers.
+func parseSemanticVersion(version string) ([]int, string, bool) {
+	m := semanticVersionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return nil, "", false
+	}
+	var numbers []int
+	for _, part := range strings.Split(m[1], ".") {
+		n, _ := strconv.Atoi(part)
+		numbers = append(numbers, n)
+	}
+	return numbers, m[2], true
+}
+
+// / <summary>Compares two versions of a file.  A date is newer than a version number, which is newer than neither,
+// / so any mix of versions sorts consistently.</summary>
+// / <returns>Positive if a is newer, negative if b is, 0 if they're the same.</returns>
+func compareVersions(a versionedFile, b versionedFile) int {
+	dateA, isDateA := parseDateVersion(a.version)
+	dateB, isDateB := parseDateVersion(b.version)
+	numbersA, preA, isNumberA := parseSemanticVersion(a.version)
+	numbersB, preB, isNumberB := parseSemanticVersion(b.version)
+	if isDateA != isDateB {
+		return IIFInt(isDateA, 1, -1)
+	}
+	if isDateA {
+		if !dateA.Equal(dateB) {
+			return IIFInt(dateA.After(dateB), 1, -1)
+		}
+	} else if isNumberA != isNumberB {
+		return IIFInt(isNumberA, 1, -1)
+	} else if isNumberA {
+		for i := 0; i < max(len(numbersA), len(numbersB)); i++ {
+			var partA, partB int
+			if i < len(numbersA) {
+				partA = numbersA[i]
+			}
+			if i < len(numbersB) {
+				partB = numbersB[i]
+			}
+			if partA != partB {
+				return IIFInt(partA > partB, 1, -1)
+			}
+		}
+		if preA != preB { // A release is newer than its pre-releases.
+			if len(preA) == 0 || len(preB) == 0 {
+				return IIFInt(len(preA) == 0, 1, -1)
+			}
+			return strings.Compare(preA, preB)
+		}
+	}
+	if !a.modTime.Equal(b.modTime) {
+		return IIFInt(a.modTime.After(b.modTime), 1, -1)
+	}
+	return 0
+}
+
+// / <summary>Makes the candidates for version selection from files in a folder.</summary>
+func versionedFiles(folder string, files []string, skipOlderMatch string) []versionedFile {
+	var candidates []versionedFile
+	for _, file := range files {
+		key, version, ok := versionKey(file, skipOlderMatch)
+		if !ok {
+			continue
+		}
+		candidate := versionedFile{path: filepath.Join(folder, file), key: key, version: version}
+		if info, err := os.Stat(candidate.path); err == nil {
+			candidate.modTime = info.ModTime()
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// / <summary>Picks the newest -keep-newest files of each group.</summary>
+// / <returns>Paths of the files not to read.</returns>
+func OlderVersions(candidates []versionedFile) map[string]bool {
+	groups := make(map[string][]versionedFile)
+	for _, candidate := range candidates {
+		groups[candidate.key] = append(groups[candidate.key], candidate)
+	}
+	older := make(map[string]bool)
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			return compareVersions(group[i], group[j]) > 0
+		})
+		for i := keepNewest; i < len(group); i++ {
+			older[group[i].path] = true
+		}
+	}
+	return older
+}
+
+// / <summary>Removes older versions from the files in a directory about to be scanned.</summary>
+// / <returns>The files to read.</returns>
+func SelectVersions(folder string, files []string, skipOlderMatch string) []string {
+	if !VersionSelectionActive(skipOlderMatch) {
+		return files
+	}
+	var older map[string]bool
+	if versionAcrossDirs {
+		older = make(map[string]bool)
+		for _, candidate := range versionedFiles(folder, files, skipOlderMatch) {
+			older[candidate.path] = !keptVersions[candidate.path]
+		}
+	} else {
+		older = OlderVersions(versionedFiles(folder, files, skipOlderMatch))
+	}
+	var selected []string
+	for _, file := range files {
+		if older[filepath.Join(folder, file)] {
+			excludedFileNames = append(excludedFileNames, filepath.Join(folder, file))
+		} else {
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
+
+// / <summary>-version-across-dirs first pass: notes a directory's candidates.</summary>
+func CollectVersions(folder string, files []string, skipOlderMatch string) {
+	versionCandidates = append(versionCandidates, versionedFiles(folder, files, skipOlderMatch)...)
+}
+
+// / <summary>-version-across-dirs: picks the files to read from everything collected.</summary>
+func SelectCollectedVersions() {
+	older := OlderVersions(versionCandidates)
+	keptVersions = make(map[string]bool)
+	for _, candidate := range versionCandidates {
+		if !older[candidate.path] {
+			keptVersions[candidate.path] = true
+		}
+	}
+}